@@ -124,46 +124,33 @@ func runFullCycle() error {
 	log.SetOutput(io.Discard)
 	defer log.SetOutput(os.Stderr)
 
-	// A) Extract links
-	links, err := parseMainSchedulePage(scheduleURL, false, "")
+	// A) Extract links, parse + build per-class aggregation
+	data, err := loadSchedule()
 	if err != nil {
-		return fmt.Errorf("parseMainSchedulePage: %w", err)
+		return fmt.Errorf("loadSchedule: %w", err)
 	}
 
-	// B) Parse + build per-class aggregation
-	classEvents := map[string][]ScheduleEvent{}
-	for _, link := range links {
-		events, err := parseScheduleDetails(link)
-		if err != nil {
-			continue // Ignore single-course failures in batch tests
-		}
-		if len(events) == 0 {
-			continue
-		}
-
-		// Individual ICS
-		if err := generateICS(link.CourseName, events); err != nil {
+	// B) Individual ICS
+	for courseName, events := range data.Courses {
+		if err := generateICS(courseName, events); err != nil {
 			return err
 		}
-
-		// Aggregate
-		classKey := extractClassKey(link.CourseName)
-		classEvents[classKey] = append(classEvents[classKey], events...)
 	}
 
 	// C) Aggregated ICS
-	for classKey, evs := range classEvents {
-		if len(evs) == 0 {
-			continue
-		}
-		evs = dedupeEvents(evs)
-		if err := generateICS(classKey, evs); err != nil {
+	for classKey, evs := range data.Classes {
+		if err := generateClassCalendars(classKey, evs); err != nil {
 			return err
 		}
 	}
 
-	// D) Site
-	if err := generateSite(); err != nil {
+	// D) Room and lecturer timetables
+	if err := generateResourceCalendars(data); err != nil {
+		return err
+	}
+
+	// E) Site
+	if err := generateSite(data); err != nil {
 		return fmt.Errorf("generateSite: %w", err)
 	}
 
@@ -0,0 +1,500 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	ics "github.com/arran4/golang-ical"
+)
+
+// jCal (RFC 7265) and xCal (RFC 6321) renderings of the calendars built in
+// writeICS. Both are generated from the same ics.Calendar as the .ics file,
+// via a small format-neutral component tree, so all three carry the same
+// components, properties, parameters and values.
+
+const xcalNamespace = "urn:ietf:params:xml:ns:icalendar-2.0"
+
+// calComponent is a component with its properties in jCal/xCal form:
+// lowercase names, typed values, VALUE folded into the type.
+type calComponent struct {
+	Name  string // vcalendar, vevent, valarm, ...
+	Props []calProp
+	Comps []calComponent
+}
+
+type calProp struct {
+	Name   string
+	Params map[string][]string // nil when empty
+	Type   string              // text, date-time, duration, ...
+	Value  string              // jCal/xCal lexical form, e.g. 2025-12-08T08:00:00Z
+}
+
+// ===== ICS -> tree =====
+
+func calendarTree(cal *ics.Calendar) calComponent {
+	root := calComponent{Name: "vcalendar"}
+	for _, p := range cal.CalendarProperties {
+		root.Props = append(root.Props, treeProp(p.BaseProperty))
+	}
+	for _, c := range cal.Components {
+		root.Comps = append(root.Comps, treeComponent(c))
+	}
+	return root
+}
+
+func treeComponent(c ics.Component) calComponent {
+	out := calComponent{Name: strings.ToLower(componentToken(c))}
+	for _, p := range c.UnknownPropertiesIANAProperties() {
+		out.Props = append(out.Props, treeProp(p.BaseProperty))
+	}
+	for _, sub := range c.SubComponents() {
+		out.Comps = append(out.Comps, treeComponent(sub))
+	}
+	return out
+}
+
+func componentToken(c ics.Component) string {
+	switch c := c.(type) {
+	case *ics.VEvent:
+		return string(ics.ComponentVEvent)
+	case *ics.VAlarm:
+		return string(ics.ComponentVAlarm)
+	case *ics.VTodo:
+		return string(ics.ComponentVTodo)
+	case *ics.VJournal:
+		return string(ics.ComponentVJournal)
+	case *ics.VBusy:
+		return string(ics.ComponentVFreeBusy)
+	case *ics.VTimezone:
+		return string(ics.ComponentVTimezone)
+	case *ics.Standard:
+		return string(ics.ComponentStandard)
+	case *ics.Daylight:
+		return string(ics.ComponentDaylight)
+	case *ics.GeneralComponent:
+		return c.Token
+	}
+	return "X-UNKNOWN"
+}
+
+// Value types follow the library's RFC 5545 defaults (GetValueType),
+// so the three outputs agree on how a value is interpreted.
+func treeProp(bp ics.BaseProperty) calProp {
+	p := calProp{
+		Name: strings.ToLower(bp.IANAToken),
+		Type: strings.ToLower(string(bp.GetValueType())),
+	}
+	for k, v := range bp.ICalParameters {
+		if strings.EqualFold(k, string(ics.ParameterValue)) {
+			continue
+		}
+		if p.Params == nil {
+			p.Params = map[string][]string{}
+		}
+		p.Params[strings.ToLower(k)] = v
+	}
+	p.Value = typedValue(p.Type, bp.Value)
+	return p
+}
+
+// iCalendar date/date-time/utc-offset values use a compact form; jCal and xCal
+// use the ISO 8601 extended form: 20251208T080000Z -> 2025-12-08T08:00:00Z.
+func typedValue(typ, v string) string {
+	switch typ {
+	case "date":
+		if len(v) == 8 {
+			return v[0:4] + "-" + v[4:6] + "-" + v[6:8]
+		}
+	case "date-time":
+		if len(v) == 8 {
+			return v[0:4] + "-" + v[4:6] + "-" + v[6:8]
+		}
+		if len(v) >= 15 && v[8] == 'T' {
+			return v[0:4] + "-" + v[4:6] + "-" + v[6:8] + "T" + v[9:11] + ":" + v[11:13] + ":" + v[13:15] + v[15:]
+		}
+	case "utc-offset":
+		if len(v) == 5 {
+			return v[0:3] + ":" + v[3:5]
+		}
+	}
+	return v
+}
+
+func icsValue(typ, v string) string {
+	switch typ {
+	case "date", "date-time":
+		return strings.NewReplacer("-", "", ":", "").Replace(v)
+	case "utc-offset":
+		return strings.ReplaceAll(v, ":", "")
+	}
+	return v
+}
+
+// ===== tree -> ICS =====
+
+// Rebuild an ics.Calendar from a tree, e.g. one read from jCal or xCal.
+func treeCalendar(root calComponent) *ics.Calendar {
+	cal := &ics.Calendar{}
+	for _, p := range root.Props {
+		cal.CalendarProperties = append(cal.CalendarProperties, ics.CalendarProperty{BaseProperty: icsProp(p)})
+	}
+	for _, c := range root.Comps {
+		cal.Components = append(cal.Components, icsComponent(c))
+	}
+	return cal
+}
+
+func icsComponent(c calComponent) ics.Component {
+	base := ics.ComponentBase{}
+	for _, p := range c.Props {
+		base.Properties = append(base.Properties, ics.IANAProperty{BaseProperty: icsProp(p)})
+	}
+	for _, sub := range c.Comps {
+		base.Components = append(base.Components, icsComponent(sub))
+	}
+
+	switch token := strings.ToUpper(c.Name); ics.ComponentType(token) {
+	case ics.ComponentVEvent:
+		return &ics.VEvent{ComponentBase: base}
+	case ics.ComponentVAlarm:
+		return &ics.VAlarm{ComponentBase: base}
+	case ics.ComponentVTodo:
+		return &ics.VTodo{ComponentBase: base}
+	case ics.ComponentVJournal:
+		return &ics.VJournal{ComponentBase: base}
+	case ics.ComponentVFreeBusy:
+		return &ics.VBusy{ComponentBase: base}
+	case ics.ComponentVTimezone:
+		return &ics.VTimezone{ComponentBase: base}
+	case ics.ComponentStandard:
+		return &ics.Standard{ComponentBase: base}
+	case ics.ComponentDaylight:
+		return &ics.Daylight{ComponentBase: base}
+	default:
+		return &ics.GeneralComponent{ComponentBase: base, Token: token}
+	}
+}
+
+func icsProp(p calProp) ics.BaseProperty {
+	bp := ics.BaseProperty{
+		IANAToken:      strings.ToUpper(p.Name),
+		ICalParameters: map[string][]string{},
+	}
+	for k, v := range p.Params {
+		bp.ICalParameters[strings.ToUpper(k)] = v
+	}
+	// Only spell out VALUE when the type is not the property's default.
+	if bp.GetValueType() != ics.ValueDataType(strings.ToUpper(p.Type)) && p.Type != "unknown" {
+		bp.ICalParameters[string(ics.ParameterValue)] = []string{strings.ToUpper(p.Type)}
+	}
+	bp.Value = icsValue(p.Type, p.Value)
+	return bp
+}
+
+// ===== jCal =====
+
+func (c calComponent) MarshalJSON() ([]byte, error) {
+	props := make([]any, 0, len(c.Props))
+	for _, p := range c.Props {
+		params := map[string]any{}
+		for k, v := range p.Params {
+			if len(v) == 1 {
+				params[k] = v[0]
+			} else {
+				params[k] = v
+			}
+		}
+		props = append(props, []any{p.Name, params, p.Type, jcalValue(p)})
+	}
+	comps := c.Comps
+	if comps == nil {
+		comps = []calComponent{}
+	}
+	return json.Marshal([]any{c.Name, props, comps})
+}
+
+// jCal carries integers and floats as JSON numbers.
+func jcalValue(p calProp) any {
+	switch p.Type {
+	case "integer":
+		if n, err := strconv.Atoi(p.Value); err == nil {
+			return n
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(p.Value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		return strings.EqualFold(p.Value, "TRUE")
+	}
+	return p.Value
+}
+
+func writeJCal(w io.Writer, root calComponent) error {
+	js, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(js, '\n'))
+	return err
+}
+
+func readJCal(r io.Reader) (calComponent, error) {
+	var raw []any
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return calComponent{}, err
+	}
+	return jcalComponent(raw)
+}
+
+func jcalComponent(raw []any) (calComponent, error) {
+	if len(raw) != 3 {
+		return calComponent{}, fmt.Errorf("jcal: component needs 3 members, got %d", len(raw))
+	}
+	name, _ := raw[0].(string)
+	props, ok1 := raw[1].([]any)
+	comps, ok2 := raw[2].([]any)
+	if name == "" || !ok1 || !ok2 {
+		return calComponent{}, fmt.Errorf("jcal: malformed component %v", raw[0])
+	}
+
+	c := calComponent{Name: name}
+	for _, rp := range props {
+		arr, ok := rp.([]any)
+		if !ok || len(arr) < 4 {
+			return c, fmt.Errorf("jcal: malformed property in %s", name)
+		}
+		p := calProp{}
+		p.Name, _ = arr[0].(string)
+		p.Type, _ = arr[2].(string)
+		if params, ok := arr[1].(map[string]any); ok {
+			for k, v := range params {
+				if p.Params == nil {
+					p.Params = map[string][]string{}
+				}
+				switch v := v.(type) {
+				case []any:
+					for _, s := range v {
+						p.Params[k] = append(p.Params[k], fmt.Sprint(s))
+					}
+				default:
+					p.Params[k] = []string{fmt.Sprint(v)}
+				}
+			}
+		}
+		// Multi-valued properties are not emitted by this exporter; the
+		// first value is kept.
+		switch v := arr[3].(type) {
+		case string:
+			p.Value = v
+		case json.Number:
+			p.Value = v.String()
+		case bool:
+			p.Value = strings.ToUpper(strconv.FormatBool(v))
+		default:
+			return c, fmt.Errorf("jcal: unsupported value for %s", p.Name)
+		}
+		c.Props = append(c.Props, p)
+	}
+	for _, rc := range comps {
+		arr, ok := rc.([]any)
+		if !ok {
+			return c, fmt.Errorf("jcal: malformed subcomponent in %s", name)
+		}
+		sub, err := jcalComponent(arr)
+		if err != nil {
+			return c, err
+		}
+		c.Comps = append(c.Comps, sub)
+	}
+	return c, nil
+}
+
+// ===== xCal =====
+
+func writeXCal(w io.Writer, root calComponent) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+
+	start := xml.StartElement{Name: xml.Name{Local: "icalendar"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: xcalNamespace}}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeXCalComponent(enc, root); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(start.End()); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func encodeXCalComponent(enc *xml.Encoder, c calComponent) error {
+	el := xml.StartElement{Name: xml.Name{Local: c.Name}}
+	if err := enc.EncodeToken(el); err != nil {
+		return err
+	}
+
+	if len(c.Props) > 0 {
+		props := xml.StartElement{Name: xml.Name{Local: "properties"}}
+		if err := enc.EncodeToken(props); err != nil {
+			return err
+		}
+		for _, p := range c.Props {
+			if err := encodeXCalProp(enc, p); err != nil {
+				return err
+			}
+		}
+		if err := enc.EncodeToken(props.End()); err != nil {
+			return err
+		}
+	}
+
+	if len(c.Comps) > 0 {
+		comps := xml.StartElement{Name: xml.Name{Local: "components"}}
+		if err := enc.EncodeToken(comps); err != nil {
+			return err
+		}
+		for _, sub := range c.Comps {
+			if err := encodeXCalComponent(enc, sub); err != nil {
+				return err
+			}
+		}
+		if err := enc.EncodeToken(comps.End()); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(el.End())
+}
+
+func encodeXCalProp(enc *xml.Encoder, p calProp) error {
+	el := xml.StartElement{Name: xml.Name{Local: p.Name}}
+	if err := enc.EncodeToken(el); err != nil {
+		return err
+	}
+
+	if len(p.Params) > 0 {
+		params := xml.StartElement{Name: xml.Name{Local: "parameters"}}
+		if err := enc.EncodeToken(params); err != nil {
+			return err
+		}
+		names := make([]string, 0, len(p.Params))
+		for k := range p.Params {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			pel := xml.StartElement{Name: xml.Name{Local: k}}
+			if err := enc.EncodeToken(pel); err != nil {
+				return err
+			}
+			for _, v := range p.Params[k] {
+				if err := encodeXCalValue(enc, "text", v); err != nil {
+					return err
+				}
+			}
+			if err := enc.EncodeToken(pel.End()); err != nil {
+				return err
+			}
+		}
+		if err := enc.EncodeToken(params.End()); err != nil {
+			return err
+		}
+	}
+
+	if err := encodeXCalValue(enc, p.Type, p.Value); err != nil {
+		return err
+	}
+	return enc.EncodeToken(el.End())
+}
+
+func encodeXCalValue(enc *xml.Encoder, typ, v string) error {
+	el := xml.StartElement{Name: xml.Name{Local: typ}}
+	if err := enc.EncodeToken(el); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(xml.CharData(v)); err != nil {
+		return err
+	}
+	return enc.EncodeToken(el.End())
+}
+
+// xmlNode is a generic element used to read xCal back.
+type xmlNode struct {
+	XMLName xml.Name
+	Nodes   []xmlNode `xml:",any"`
+	Text    string    `xml:",chardata"`
+}
+
+func readXCal(r io.Reader) (calComponent, error) {
+	var doc xmlNode
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return calComponent{}, err
+	}
+	if doc.XMLName.Local != "icalendar" || doc.XMLName.Space != xcalNamespace {
+		return calComponent{}, fmt.Errorf("xcal: unexpected root element %s", doc.XMLName.Local)
+	}
+	if len(doc.Nodes) != 1 {
+		return calComponent{}, fmt.Errorf("xcal: expected one vcalendar, got %d", len(doc.Nodes))
+	}
+	return xcalComponent(doc.Nodes[0]), nil
+}
+
+func xcalComponent(n xmlNode) calComponent {
+	c := calComponent{Name: n.XMLName.Local}
+	for _, section := range n.Nodes {
+		switch section.XMLName.Local {
+		case "properties":
+			for _, pn := range section.Nodes {
+				c.Props = append(c.Props, xcalProp(pn))
+			}
+		case "components":
+			for _, cn := range section.Nodes {
+				c.Comps = append(c.Comps, xcalComponent(cn))
+			}
+		}
+	}
+	return c
+}
+
+func xcalProp(n xmlNode) calProp {
+	p := calProp{Name: n.XMLName.Local}
+	for _, child := range n.Nodes {
+		if child.XMLName.Local == "parameters" {
+			for _, param := range child.Nodes {
+				if p.Params == nil {
+					p.Params = map[string][]string{}
+				}
+				for _, v := range param.Nodes {
+					p.Params[param.XMLName.Local] = append(p.Params[param.XMLName.Local], v.Text)
+				}
+			}
+			continue
+		}
+		// Multi-valued properties are not emitted by this exporter; the
+		// first value is kept.
+		if p.Type == "" {
+			p.Type = child.XMLName.Local
+			p.Value = child.Text
+		}
+	}
+	return p
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+)
+
+// Write a calendar with writeICS and check that the .ics, .jcal.json and
+// .xcs files describe the same calendar, and that each format converts
+// into the others without loss.
+func TestCalendarFormatsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	defer func(old string) { outputDir = old }(outputDir)
+	outputDir = dir
+
+	loc, _ := time.LoadLocation(tzID)
+	start := time.Date(2025, 12, 8, 9, 0, 0, 0, loc)
+	events := []ScheduleEvent{
+		{
+			CourseName:  "DBWINFO-A04 - 3. Block",
+			Summary:     "IBL III (Vorlesung)",
+			Location:    "NK: 2.05",
+			Description: "Course: DBWINFO-A04 - 3. Block\nNote: Raum; Gebäude <B>, \"neu\" & mehr",
+			Start:       start,
+			End:         start.Add(90 * time.Minute),
+			Status:      StatusConfirmed,
+		},
+		{
+			CourseName: "DBWINFO-A04 - 3. Block",
+			Summary:    "Cancelled: Statistik",
+			Start:      start.Add(24 * time.Hour),
+			End:        start.Add(26 * time.Hour),
+			Status:     StatusCancelled,
+		},
+	}
+	alarms := func(ScheduleEvent) []reminder {
+		return []reminder{{Before: 7 * 24 * time.Hour, Text: "Upcoming exam"}, {Before: 15 * time.Minute, Text: "Soon"}}
+	}
+	if err := writeICS("DBWINFO-A04", "DBWINFO-A04", "sub/DBWINFO-A04", events, alarms); err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Join(dir, "sub", "DBWINFO-A04")
+
+	// ICS as parsed from disk is the reference.
+	icsFile, err := os.ReadFile(base + ".ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cal, err := ics.ParseCalendar(bytes.NewReader(icsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromICS := calendarTree(cal)
+
+	readFile := func(name string, read func(*bytes.Reader) (calComponent, error)) calComponent {
+		t.Helper()
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := read(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		return c
+	}
+	fromJCal := readFile(base+".jcal.json", func(r *bytes.Reader) (calComponent, error) { return readJCal(r) })
+	fromXCal := readFile(base+".xcs", func(r *bytes.Reader) (calComponent, error) { return readXCal(r) })
+
+	if !reflect.DeepEqual(fromICS, fromJCal) {
+		t.Errorf("jCal differs from ICS:\n ics:  %+v\n jcal: %+v", fromICS, fromJCal)
+	}
+	if !reflect.DeepEqual(fromICS, fromXCal) {
+		t.Errorf("xCal differs from ICS:\n ics:  %+v\n xcal: %+v", fromICS, fromXCal)
+	}
+
+	// Spot checks on the typed values and the properties that matter.
+	if fromICS.Comps[0].Comps == nil || len(fromICS.Comps) != 2 {
+		t.Fatalf("unexpected structure: %+v", fromICS)
+	}
+	want := map[string]string{
+		"tzid":    "text:" + tzID,
+		"uid":     fmt.Sprintf("text:DBWINFO-A04-%d-0", start.Unix()),
+		"dtstart": "date-time:2025-12-08T08:00:00Z",
+		"status":  "text:CONFIRMED",
+		"trigger": "duration:-P7D",
+	}
+	got := map[string]string{}
+	collect := func(c calComponent) {
+		for _, p := range c.Props {
+			if _, ok := got[p.Name]; !ok {
+				got[p.Name] = p.Type + ":" + p.Value
+			}
+		}
+	}
+	collect(fromXCal)
+	collect(fromXCal.Comps[0])
+	collect(fromXCal.Comps[0].Comps[0])
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+	if len(fromXCal.Comps[1].Comps) != 0 {
+		t.Errorf("cancelled event has alarms in xCal")
+	}
+
+	// jCal -> ICS -> jCal and xCal -> ICS -> xCal are lossless.
+	for name, tree := range map[string]calComponent{"jcal": fromJCal, "xcal": fromXCal} {
+		back, err := ics.ParseCalendar(strings.NewReader(treeCalendar(tree).Serialize()))
+		if err != nil {
+			t.Fatalf("%s -> ics: %v", name, err)
+		}
+		if again := calendarTree(back); !reflect.DeepEqual(again, tree) {
+			t.Errorf("%s -> ics -> tree differs:\n got:  %+v\n want: %+v", name, again, tree)
+		}
+	}
+}
+
+// A module-less event that gets cancelled keeps its UID in the
+// current-block calendar, so clients update it instead of adding a copy.
+func TestCurrentCalendarUIDSurvivesCancellation(t *testing.T) {
+	dir := t.TempDir()
+	defer func(old string) { outputDir = old }(outputDir)
+	outputDir = dir
+
+	loc, _ := time.LoadLocation(tzID)
+	start := time.Date(2025, 12, 8, 9, 0, 0, 0, loc)
+	exam := ScheduleEvent{CourseName: "DBWINFO-A04 - 3. Block", EventType: "Klausur", Start: start, End: start.Add(2 * time.Hour), Status: StatusConfirmed}
+	lecture := ScheduleEvent{CourseName: "DBWINFO-A04 - 3. Block", Module: "IBL III", EventType: "Vorlesung", Start: start.Add(-24 * time.Hour), End: start.Add(-22 * time.Hour)}
+
+	uids := func(events ...ScheduleEvent) []string {
+		for i := range events {
+			events[i].Summary = summarizeEvent(events[i])
+		}
+		if err := writeICSUIDs("DBWINFO-A04", "current", events, nil, eventUIDs("DBWINFO-A04_Current")); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(filepath.Join(dir, "current.ics"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cal, err := ics.ParseCalendar(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, ev := range cal.Events() {
+			out = append(out, ev.Id())
+		}
+		return out
+	}
+
+	before := uids(lecture, exam)
+	cancelled := exam
+	cancelled.Status = StatusCancelled
+	// The previous block dropped out as well, so the position changes too.
+	after := uids(cancelled)
+	if len(before) != 2 || len(after) != 1 || after[0] != before[1] {
+		t.Errorf("UIDs before %v, after cancelling %v", before, after)
+	}
+}
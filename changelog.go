@@ -0,0 +1,329 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Change history of the class and course calendars (changes-<calendar>.html).
+// Every run compares a calendar's events with its snapshot from the previous
+// run in the state file. Detected changes are kept for
+// ASW_CHANGELOG_RETENTION and the newest ASW_CHANGELOG_LIMIT are shown.
+
+var (
+	changelogRetention = getenv("ASW_CHANGELOG_RETENTION", "30d")
+	changelogLimit     = getenv("ASW_CHANGELOG_LIMIT", "50")
+)
+
+// What the changelog compares of an event; times as in the JSON export.
+type eventSnapshot struct {
+	Summary    string `json:"summary"`
+	Start      string `json:"start"`
+	End        string `json:"end"`
+	Location   string `json:"location,omitempty"`
+	Instructor string `json:"instructor,omitempty"`
+	Status     string `json:"status"`
+}
+
+// Change kinds
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed" // same event ID, other room, lecturer, status or title
+	changeMoved   = "moved"   // removed and added again at another time
+)
+
+// Compared fields, in display order
+var changeFields = []string{"time", "summary", "location", "instructor", "status"}
+
+type scheduleChange struct {
+	Detected time.Time      `json:"detected"`
+	Kind     string         `json:"kind"`
+	Before   *eventSnapshot `json:"before,omitempty"`
+	After    *eventSnapshot `json:"after,omitempty"`
+	Fields   []string       `json:"fields,omitempty"` // changed and moved only
+}
+
+// Snapshot of a calendar by event ID.
+func snapshotEvents(events []ScheduleEvent) map[string]eventSnapshot {
+	out := map[string]eventSnapshot{}
+	for _, j := range toJSONEvents(events) {
+		out[j.ID] = eventSnapshot{j.Summary, j.Start, j.End, j.Location, j.Instructor, j.Status}
+	}
+	return out
+}
+
+func (s eventSnapshot) field(name string) string {
+	switch name {
+	case "time":
+		return s.Start + "/" + s.End
+	case "summary":
+		return s.Summary
+	case "location":
+		return s.Location
+	case "instructor":
+		return s.Instructor
+	case "status":
+		return s.Status
+	}
+	return ""
+}
+
+func changedFields(a, b eventSnapshot) []string {
+	var out []string
+	for _, f := range changeFields {
+		if a.field(f) != b.field(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Changes between two snapshots of one calendar, by event start. An event
+// that disappears while one with the same title appears is reported as
+// moved; several of them are paired in chronological order.
+func diffSnapshots(old, cur map[string]eventSnapshot, now time.Time) []scheduleChange {
+	var out []scheduleChange
+	removed := map[string][]eventSnapshot{}
+	added := map[string][]eventSnapshot{}
+	for id, b := range old {
+		a, ok := cur[id]
+		if !ok {
+			removed[b.Summary] = append(removed[b.Summary], b)
+			continue
+		}
+		if fields := changedFields(b, a); len(fields) > 0 {
+			out = append(out, scheduleChange{Detected: now, Kind: changeChanged, Before: &b, After: &a, Fields: fields})
+		}
+	}
+	for id, a := range cur {
+		if _, ok := old[id]; !ok {
+			added[a.Summary] = append(added[a.Summary], a)
+		}
+	}
+
+	byStart := func(s []eventSnapshot) {
+		sort.Slice(s, func(i, j int) bool { return s[i].Start+s[i].End < s[j].Start+s[j].End })
+	}
+	for summary, gone := range removed {
+		byStart(gone)
+		came := added[summary]
+		byStart(came)
+		for i := range gone {
+			b := gone[i]
+			if i < len(came) {
+				a := came[i]
+				out = append(out, scheduleChange{Detected: now, Kind: changeMoved, Before: &b, After: &a, Fields: changedFields(b, a)})
+				continue
+			}
+			out = append(out, scheduleChange{Detected: now, Kind: changeRemoved, Before: &b})
+		}
+		if len(came) > len(gone) {
+			added[summary] = came[len(gone):]
+		} else {
+			delete(added, summary)
+		}
+	}
+	for _, came := range added {
+		for i := range came {
+			a := came[i]
+			out = append(out, scheduleChange{Detected: now, Kind: changeAdded, After: &a})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].event(), out[j].event()
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		if a.Summary != b.Summary {
+			return a.Summary < b.Summary
+		}
+		return out[i].Kind < out[j].Kind
+	})
+	return out
+}
+
+// The event as it is now, or as it was for removed events.
+func (c scheduleChange) event() eventSnapshot {
+	if c.After != nil {
+		return *c.After
+	}
+	return *c.Before
+}
+
+// Compare the class and course calendars with the previous run and drop
+// history older than keep. A calendar missing the events of a failed source
+// keeps its old snapshot, so a failed fetch does not show up as removed
+// events and then as added ones.
+func (st *runState) recordChanges(data scheduleData, cals []calendarEntry, now time.Time, keep time.Duration) {
+	incomplete := map[string]bool{}
+	for course := range data.Failed {
+		incomplete[sanitizeName(course)] = true
+		incomplete[sanitizeName(extractClassKey(course))] = true
+	}
+
+	seen := map[string]bool{}
+	for _, c := range cals {
+		if c.Kind != "class" && c.Kind != "course" {
+			continue
+		}
+		seen[c.File] = true
+		if incomplete[c.File] {
+			continue
+		}
+		snap := snapshotEvents(c.Events)
+		// The first run of a calendar is the baseline.
+		if old, ok := st.Events[c.File]; ok {
+			if changes := diffSnapshots(old, snap, now); len(changes) > 0 {
+				st.Changes[c.File] = append(changes, st.Changes[c.File]...)
+			}
+		}
+		st.Events[c.File] = snap
+	}
+	for file := range st.Events {
+		if !seen[file] && !incomplete[file] {
+			delete(st.Events, file)
+			delete(st.Changes, file)
+		}
+	}
+
+	for file, list := range st.Changes {
+		n := 0
+		for n < len(list) && now.Sub(list[n].Detected) <= keep {
+			n++
+		}
+		if n == 0 {
+			delete(st.Changes, file)
+		} else {
+			st.Changes[file] = list[:n]
+		}
+	}
+}
+
+// File name of a changelog page, relative to the language folder.
+func changelogFile(file string) string {
+	return "changes-" + file + ".html"
+}
+
+// Write a changelog page for every class and course calendar into the
+// language folder.
+func renderChangelogs(data scheduleData, l locale) error {
+	for _, name := range eventKeys(data.Classes) {
+		if err := renderChangelogPage(l, name, sanitizeName(name)); err != nil {
+			return fmt.Errorf("changelog %s: %w", name, err)
+		}
+	}
+	for _, name := range eventKeys(data.Courses) {
+		if err := renderChangelogPage(l, name, sanitizeName(name)); err != nil {
+			return fmt.Errorf("changelog %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Data of a changelog page (pages/changes.html).
+type changesPage struct {
+	pageData
+	KeepDays int
+	Groups   []changeGroup // one per run, newest first
+}
+
+// The changes detected in one run.
+type changeGroup struct {
+	Detected string
+	Changes  []changeItem
+}
+
+// A change with its texts in the page language.
+type changeItem struct {
+	scheduleChange
+	Label   string // added, removed, ...
+	Summary string
+	Details string         // added and removed: time, location, lecturer
+	Diffs   []changedField // changed and moved
+}
+
+type changedField struct {
+	Label  string
+	Before string
+	After  string
+}
+
+func renderChangelogPage(l locale, label, file string) error {
+	page := changesPage{
+		pageData: l.newPage(changelogFile(file), l.T("page.changes", label), l.T("page.changes.sub")),
+		KeepDays: int((siteRun.changeKeep + 23*time.Hour) / (24 * time.Hour)),
+	}
+
+	changes := siteRun.changes[file]
+	if len(changes) > siteRun.changeLimit {
+		changes = changes[:siteRun.changeLimit]
+	}
+	for len(changes) > 0 {
+		n := 1
+		for n < len(changes) && changes[n].Detected.Equal(changes[0].Detected) {
+			n++
+		}
+		g := changeGroup{Detected: displayTime(changes[0].Detected)}
+		for _, c := range changes[:n] {
+			g.Changes = append(g.Changes, newChangeItem(l, c))
+		}
+		page.Groups = append(page.Groups, g)
+		changes = changes[n:]
+	}
+
+	return renderThemePage("changes.html", page)
+}
+
+func newChangeItem(l locale, c scheduleChange) changeItem {
+	e := c.event()
+	item := changeItem{scheduleChange: c, Label: l.T("chg." + c.Kind), Summary: e.Summary}
+
+	switch c.Kind {
+	case changeAdded, changeRemoved:
+		parts := []string{snapshotValue(l, e, "time")}
+		for _, f := range []string{"location", "instructor"} {
+			if v := e.field(f); v != "" {
+				parts = append(parts, v)
+			}
+		}
+		if e.Status != string(StatusConfirmed) {
+			parts = append(parts, snapshotValue(l, e, "status"))
+		}
+		item.Details = strings.Join(parts, " · ")
+	default:
+		for _, f := range c.Fields {
+			item.Diffs = append(item.Diffs, changedField{l.T("chg.f." + f), snapshotValue(l, *c.Before, f), snapshotValue(l, *c.After, f)})
+		}
+	}
+	return item
+}
+
+// A field of a snapshot as shown on the page.
+func snapshotValue(l locale, s eventSnapshot, field string) string {
+	switch field {
+	case "time":
+		start, err1 := time.Parse(time.RFC3339, s.Start)
+		end, err2 := time.Parse(time.RFC3339, s.End)
+		if err1 != nil || err2 != nil {
+			return s.Start + " – " + s.End
+		}
+		start, end = start.In(displayLocation()), end.In(displayLocation())
+		if start.Format(dateFormat) != end.Format(dateFormat) {
+			return l.weekday(start.Weekday()) + " " + displayTime(start) + " – " + l.weekday(end.Weekday()) + " " + displayTime(end)
+		}
+		return l.weekday(start.Weekday()) + " " + displayTime(start) + "–" + end.Format("15:04")
+	case "status":
+		if s.Status == string(StatusConfirmed) || s.Status == "" {
+			return l.T("chg.confirmed")
+		}
+		return l.T("ics.status." + s.Status)
+	}
+	if v := s.field(field); v != "" {
+		return v
+	}
+	return "–"
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordChanges(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2025, 12, d, h, 0, 0, 0, time.UTC) }
+	ev := func(summary string, d, h int, room string) ScheduleEvent {
+		return ScheduleEvent{CourseName: "DBWINFO-A04 - 3. Block", Summary: summary, Start: day(d, h), End: day(d, h).Add(90 * time.Minute), Location: room, Status: StatusConfirmed}
+	}
+	course := "DBWINFO-A04 - 3. Block"
+	file := sanitizeName(course)
+	run := func(st *runState, events []ScheduleEvent, failed bool, now time.Time) {
+		data := newScheduleData()
+		if failed {
+			data.Failed[course] = "timeout"
+		} else {
+			data.Courses[course] = events
+		}
+		st.recordChanges(data, publishedCalendars(data), now, 7*24*time.Hour)
+	}
+	st := newRunState()
+
+	// Run 1 is the baseline.
+	run(st, []ScheduleEvent{ev("IBL III", 8, 9, "NK 2.05"), ev("Recht", 9, 9, "NK 2.05"), ev("Mathe", 10, 9, "")}, false, day(1, 6))
+	if len(st.Changes) != 0 {
+		t.Fatalf("baseline recorded changes: %+v", st.Changes)
+	}
+
+	// Run 2: room change, a moved lecture, a removed and an added event.
+	run(st, []ScheduleEvent{ev("IBL III", 8, 9, "NK 1.12"), ev("Recht", 11, 13, "NK 2.05"), ev("Englisch", 12, 9, "")}, false, day(2, 6))
+	var got []string
+	for _, c := range st.Changes[file] {
+		got = append(got, c.Kind+" "+c.event().Summary+" "+strings.Join(c.Fields, ","))
+	}
+	want := []string{"changed IBL III location", "removed Mathe ", "moved Recht time", "added Englisch "}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("run 2 changes:\n got %q\nwant %q", got, want)
+	}
+
+	// Run 3: the fetch fails; the snapshot and the history are kept.
+	run(st, nil, true, day(3, 6))
+	if len(st.Events[file]) != 3 || len(st.Changes[file]) != 4 {
+		t.Errorf("run 3: %d events, %d changes", len(st.Events[file]), len(st.Changes[file]))
+	}
+
+	// Run 4: back with the same events, after the retention period.
+	run(st, []ScheduleEvent{ev("IBL III", 8, 9, "NK 1.12"), ev("Recht", 11, 13, "NK 2.05"), ev("Englisch", 12, 9, "")}, false, day(10, 6))
+	if _, ok := st.Changes[file]; ok {
+		t.Errorf("run 4: expired changes kept: %+v", st.Changes[file])
+	}
+}
+
+// A cancelled event without a module is the same event with a new status,
+// not a removed and an added one.
+func TestDiffSnapshotsCancelled(t *testing.T) {
+	start := time.Date(2025, 12, 8, 9, 0, 0, 0, time.UTC)
+	exam := ScheduleEvent{CourseName: "DBWINFO-A04 - 3. Block", EventType: "Klausur", Start: start, End: start.Add(2 * time.Hour), Status: StatusConfirmed}
+	exam.Summary = summarizeEvent(exam)
+	cancelled := exam
+	cancelled.Status = StatusCancelled
+	cancelled.Summary = summarizeEvent(cancelled)
+
+	changes := diffSnapshots(snapshotEvents([]ScheduleEvent{exam}), snapshotEvents([]ScheduleEvent{cancelled}), start)
+	if len(changes) != 1 || changes[0].Kind != changeChanged || !strings.Contains(strings.Join(changes[0].Fields, ","), "status") {
+		t.Errorf("changes = %+v, want one changed status", changes)
+	}
+}
+
+func TestSnapshotValue(t *testing.T) {
+	s := eventSnapshot{Summary: "IBL III", Start: "2025-12-08T09:00:00+01:00", End: "2025-12-08T10:30:00+01:00", Status: "CANCELLED"}
+	en := locale{Lang: "en", Langs: []string{"de", "en"}}
+	de := locale{Lang: "de", Langs: []string{"de", "en"}}
+	if got := snapshotValue(de, s, "time"); got != "Mo 08.12.2025 09:00–10:30" {
+		t.Errorf("time = %q", got)
+	}
+	if got := snapshotValue(en, s, "status"); got != "cancelled" {
+		t.Errorf("status = %q", got)
+	}
+	if got := snapshotValue(en, s, "location"); got != "–" {
+		t.Errorf("empty location = %q", got)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// cellFields is the result of classifying the lines of a td.v cell.
+type cellFields struct {
+	EventType  string
+	Module     string
+	Group      string
+	Location   string
+	Room       string
+	Site       string
+	Instructor string
+	IsOnline   bool
+	Extra      []string
+}
+
+var (
+	// Known event types. The first line consisting of one of these (optionally
+	// combined with a second one or followed by a bracket note) becomes EventType.
+	eventTypeNames = `vorlesung|(?:ü|ue)bung|seminar|tutorium|praktikum|labor|` +
+		`klausur|nachklausur|(?:m(?:ü|ue)ndliche\s+)?pr(?:ü|ue)fung|pr(?:ä|ae)sentation|kolloquium|` +
+		`projekt(?:arbeit|woche)?|workshop|exkursion|planspiel|sprechstunde|selbststudium|` +
+		`info\w*veranstaltung|blockveranstaltung|veranstaltung|abgabe`
+	eventTypeRe = regexp.MustCompile(`(?i)^(?:` + eventTypeNames + `)(?:\s*[/+&]\s*(?:` + eventTypeNames + `))?(?:\s*\(.*\))?$`)
+
+	// Site-prefixed locations as exported by sked: "NK: 2.05", "EXT: Online".
+	// Only known site codes count, so module lines like "BWL: Grundlagen"
+	// are not mistaken for locations.
+	sitePrefixes = splitList(getenv("ASW_SITE_PREFIXES", "NK,EXT"))
+	sitePrefixRe = compileSitePrefixes(sitePrefixes)
+
+	// Bare rooms: "Raum 2.05", "2.05", "A 1.12", "Hörsaal 3".
+	roomRe = regexp.MustCompile(`(?i)^(raum\s+\S+|h(ö|oe)rsaal\s*\S*|aula|[A-Z]?\s?\d{1,2}\.\d{1,3}[a-z]?)$`)
+
+	onlineRe = regexp.MustCompile(`(?i)\b(online|zoom|teams|webex|virtuell)\b`)
+
+	// Lecturers are listed with a title or salutation, or with a "Doz.:" label.
+	instructorRe      = regexp.MustCompile(`^((?:Prof\.|Dr\.|Dipl\.-?\S*|Hr\.|Fr\.|M\.\s?A\.|M\.\s?Sc\.|B\.\s?A\.)\s*\S.*|(?:Herr|Frau)\s+\S.*)$`)
+	instructorLabelRe = regexp.MustCompile(`(?i)^(?:doz(?:ent(?:in)?)?\.?|lehrende[r]?)\s*:\s*(.+)$`)
+
+	// Groups either stand on their own line ("Gruppe A", "Gr. 2", "Zug B")
+	// or trail the module line ("IBL III - Gruppe A", "IBL III (Gr. 2)").
+	groupLineRe   = regexp.MustCompile(`(?i)^(gruppe|gr\.|zug)\s*\S+$`)
+	groupSuffixRe = regexp.MustCompile(`(?i)\s*[-–/(]\s*((?:gruppe|gr\.|zug)\s*[A-Z0-9]+)\)?\s*$`)
+
+	timeLineRe = regexp.MustCompile(`(?i)^\d{1,2}:\d{2}\s*-\s*\d{1,2}:\d{2}(\s*uhr)?$`)
+)
+
+func compileSitePrefixes(prefixes []string) *regexp.Regexp {
+	parts := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		parts = append(parts, regexp.QuoteMeta(p))
+	}
+	if len(parts) == 0 {
+		// Matches nothing.
+		return regexp.MustCompile(`$^`)
+	}
+	return regexp.MustCompile(`^(` + strings.Join(parts, "|") + `):\s*(.*)$`)
+}
+
+// Classify cell lines by content rather than position.
+// The sked export usually lists time, type, module and location in that order,
+// but optional lines (groups, lecturers) shift the positions around.
+// Lines no rule claims are kept as Extra. If no type keyword matches,
+// we fall back to the old positional reading: first free line is the type
+// when a module line follows it.
+func classifyCellLines(lines []string) cellFields {
+	var f cellFields
+	var free []string
+
+	for _, l := range lines {
+		switch {
+		case timeLineRe.MatchString(l):
+			// The time is parsed separately.
+		case f.EventType == "" && eventTypeRe.MatchString(l):
+			f.EventType = l
+		case f.Location == "" && sitePrefixRe.MatchString(l):
+			m := sitePrefixRe.FindStringSubmatch(l)
+			f.Location = l
+			f.Site = m[1]
+			f.Room = strings.TrimSpace(m[2])
+		case f.Location == "" && roomRe.MatchString(l):
+			f.Location = l
+			f.Room = l
+		case f.Instructor == "" && instructorLabelRe.MatchString(l):
+			f.Instructor = strings.TrimSpace(instructorLabelRe.FindStringSubmatch(l)[1])
+		case f.Instructor == "" && instructorRe.MatchString(l):
+			f.Instructor = strings.TrimSpace(instructorRe.FindStringSubmatch(l)[1])
+		case f.Group == "" && groupLineRe.MatchString(l):
+			f.Group = l
+		default:
+			free = append(free, l)
+		}
+	}
+
+	if f.EventType == "" && len(free) >= 2 {
+		f.EventType = free[0]
+		free = free[1:]
+	}
+	if len(free) > 0 {
+		f.Module = free[0]
+	}
+	if len(free) > 1 {
+		f.Extra = free[1:]
+	}
+
+	if m := groupSuffixRe.FindStringSubmatch(f.Module); len(m) == 2 && f.Group == "" {
+		f.Group = m[1]
+		f.Module = strings.TrimSpace(f.Module[:len(f.Module)-len(m[0])])
+	}
+
+	if onlineRe.MatchString(f.Location) || (f.Location == "" && anyMatch(onlineRe, lines)) {
+		f.IsOnline = true
+	}
+
+	return f
+}
+
+func anyMatch(re *regexp.Regexp, lines []string) bool {
+	for _, l := range lines {
+		if re.MatchString(l) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Consistency checks on the merged data. Conflicts usually point at data
+// errors on the ASW side or at parsing bugs on ours, so they are reported,
+// never "fixed".
+
+const (
+	conflictRoom       = "room"
+	conflictInstructor = "instructor"
+	conflictClass      = "class"
+)
+
+type conflict struct {
+	Kind    string   `json:"kind"`
+	Subject string   `json:"subject"` // room label, lecturer or class key
+	A       eventRef `json:"a"`
+	B       eventRef `json:"b"`
+}
+
+// eventRef is the part of an event needed to find it in the source.
+type eventRef struct {
+	Course   string    `json:"course"`
+	Summary  string    `json:"summary"`
+	Location string    `json:"location,omitempty"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	CellID   string    `json:"cellId,omitempty"`
+}
+
+func refOf(e ScheduleEvent) eventRef {
+	return eventRef{
+		Course:   e.CourseName,
+		Summary:  e.Summary,
+		Location: e.Location,
+		Start:    e.Start,
+		End:      e.End,
+		CellID:   e.CellID,
+	}
+}
+
+// Event types that are not mandatory attendance and may overlap other events.
+var optionalTypeRe = regexp.MustCompile(`(?i)^(sprechstunde|selbststudium|tutorium|info\w*)`)
+
+// Find all conflicts in the merged data, sorted by kind, subject and time.
+func findConflicts(data scheduleData) []conflict {
+	var out []conflict
+
+	// Same room, overlapping times, different courses. A joint lecture of
+	// several classes (same title, same times) is not a conflict.
+	for _, rc := range roomCalendars(data) {
+		for _, p := range overlappingPairs(rc.Events) {
+			a, b := p[0], p[1]
+			if a.CourseName == b.CourseName {
+				continue
+			}
+			if a.Summary == b.Summary && a.Start.Equal(b.Start) && a.End.Equal(b.End) {
+				continue
+			}
+			out = append(out, conflict{conflictRoom, rc.Label, refOf(a), refOf(b)})
+		}
+	}
+
+	// Same lecturer, overlapping times, different places.
+	for _, rc := range lecturerCalendars(data) {
+		for _, p := range overlappingPairs(rc.Events) {
+			a, b := p[0], p[1]
+			if a.Location == b.Location && a.IsOnline == b.IsOnline {
+				continue
+			}
+			out = append(out, conflict{conflictInstructor, rc.Label, refOf(a), refOf(b)})
+		}
+	}
+
+	// Overlapping mandatory events within one class. Parallel groups
+	// ("Gruppe A" / "Gruppe B") and optional formats are expected to overlap.
+	for _, classKey := range eventKeys(data.Classes) {
+		for _, p := range overlappingPairs(data.Classes[classKey]) {
+			a, b := p[0], p[1]
+			if a.Group != "" && b.Group != "" && a.Group != b.Group {
+				continue
+			}
+			if optionalTypeRe.MatchString(a.EventType) || optionalTypeRe.MatchString(b.EventType) {
+				continue
+			}
+			out = append(out, conflict{conflictClass, classKey, refOf(a), refOf(b)})
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		if out[i].Subject != out[j].Subject {
+			return out[i].Subject < out[j].Subject
+		}
+		return out[i].A.Start.Before(out[j].A.Start)
+	})
+
+	return out
+}
+
+// Return all pairs of non-cancelled events whose times overlap.
+// Events touching end-to-start do not overlap.
+func overlappingPairs(events []ScheduleEvent) [][2]ScheduleEvent {
+	evs := make([]ScheduleEvent, 0, len(events))
+	for _, e := range events {
+		if e.Status != StatusCancelled {
+			evs = append(evs, e)
+		}
+	}
+	sort.SliceStable(evs, func(i, j int) bool { return evs[i].Start.Before(evs[j].Start) })
+
+	var pairs [][2]ScheduleEvent
+	for i := range evs {
+		for j := i + 1; j < len(evs) && evs[j].Start.Before(evs[i].End); j++ {
+			pairs = append(pairs, [2]ScheduleEvent{evs[i], evs[j]})
+		}
+	}
+	return pairs
+}
+
+// Data of conflicts.html (pages/conflicts.html).
+type conflictsPage struct {
+	pageData
+	Sections []conflictSection // one per kind with conflicts
+}
+
+type conflictSection struct {
+	Title string
+	Items []conflictItem
+}
+
+type conflictItem struct {
+	Title string // subject and day
+	A, B  string
+}
+
+func renderConflictsPage(l locale, conflicts []conflict) error {
+	page := conflictsPage{pageData: l.newPage("conflicts.html", l.T("page.conflicts"), l.T("page.conflicts.sub"))}
+
+	for _, kind := range []string{conflictRoom, conflictInstructor, conflictClass} {
+		sec := conflictSection{Title: l.T("conf." + kind)}
+		for _, c := range conflicts {
+			if c.Kind == kind {
+				sec.Items = append(sec.Items, conflictItem{
+					Title: c.Subject + " · " + l.weekday(c.A.Start.Weekday()) + " " + c.A.Start.Format(dateFormat),
+					A:     describeRef(c.A),
+					B:     describeRef(c.B),
+				})
+			}
+		}
+		if len(sec.Items) > 0 {
+			page.Sections = append(page.Sections, sec)
+		}
+	}
+
+	return renderThemePage("conflicts.html", page)
+}
+
+func describeRef(r eventRef) string {
+	s := fmt.Sprintf("%s–%s %s (%s)", r.Start.Format("15:04"), r.End.Format("15:04"), r.Summary, r.Course)
+	if r.Location != "" {
+		s += " @ " + r.Location
+	}
+	return s
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindConflicts(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2025, 12, 9, h, m, 0, 0, time.UTC) }
+	ev := func(course, summary, room, instructor, group string, start, end time.Time) ScheduleEvent {
+		return ScheduleEvent{
+			CourseName: course, Summary: summary, Location: "NK: " + room,
+			Site: "NK", Room: room, Instructor: instructor, Group: group,
+			Start: start, End: end,
+		}
+	}
+
+	data := newScheduleData()
+	data.Classes["A"] = []ScheduleEvent{
+		ev("A - 3. Block", "IBL III", "2.05", "Dr. Meier", "", at(9, 0), at(10, 30)),
+		ev("A - 3. Block", "Statistik", "1.12", "Dr. Schulz", "Gruppe A", at(11, 0), at(12, 30)),
+		ev("A - 3. Block", "Statistik", "1.14", "Dr. Schulz", "Gruppe B", at(11, 0), at(12, 30)),
+	}
+	data.Classes["B"] = []ScheduleEvent{
+		// Joint lecture with class A: same room, same title and times.
+		ev("B - 3. Block", "IBL III", "2.05", "Dr. Meier", "", at(9, 0), at(10, 30)),
+		// Room taken by class A's group A, and Dr. Schulz is already teaching.
+		ev("B - 3. Block", "Marketing", "1.12", "Dr. Schulz", "", at(12, 0), at(13, 0)),
+		// Overlaps Marketing within class B.
+		ev("B - 3. Block", "Englisch", "3.01", "Fr. Smith", "", at(12, 30), at(14, 0)),
+		// Touching, not overlapping.
+		ev("B - 3. Block", "Recht", "3.01", "Fr. Smith", "", at(14, 0), at(15, 0)),
+	}
+	cancelled := ev("B - 3. Block", "Recht", "2.05", "Dr. Meier", "", at(9, 0), at(10, 0))
+	cancelled.Status = StatusCancelled
+	data.Classes["B"] = append(data.Classes["B"], cancelled)
+
+	got := map[string]int{}
+	for _, c := range findConflicts(data) {
+		got[c.Kind+" "+c.Subject]++
+	}
+	want := map[string]int{
+		"room NK 1.12":          1,
+		"instructor Dr. Schulz": 2,
+		"class B":               1,
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("conflicts = %v, want %v", got, want)
+	}
+	for k, n := range want {
+		if got[k] != n {
+			t.Errorf("conflicts[%q] = %d, want %d (all: %v)", k, got[k], n, got)
+		}
+	}
+}
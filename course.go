@@ -0,0 +1,223 @@
+package main
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Course metadata parsed once from the link text on the overview page,
+// e.g. "DBWINFO-A04 - 3. Block" or "DBING-01-2024 - 4. Blockphase". The
+// class key, and with it the ICS file names and the grouping of the site,
+// is derived from it.
+
+// Block and class key of names the parser does not recognize.
+const otherClass = "Other"
+
+type courseMeta struct {
+	Name     string // normalized link text
+	Program  string // DBWINFO, DBING, ...; "" when not recognized
+	Class    string // class or cohort token: A04, B03, 01, ...; "" for program-wide names
+	Year     int    // cohort start year, 0 when not in the name
+	Block    int    // block number, 0 when not in the name
+	Semester int    // semester number, 0 when not in the name
+}
+
+var (
+	courseProgramRe  = regexp.MustCompile(`\b(DB[A-Z]+)\b`)
+	courseLetterRe   = regexp.MustCompile(`\b(DB[A-Z]+)-([A-Z]\d{2,3})\b`)
+	courseCohortRe   = regexp.MustCompile(`\b(DB[A-Z]+)-(\d{2})\b`)
+	courseYearRe     = regexp.MustCompile(`\b((?:19|20)\d{2})\b`)
+	courseBlockRe    = regexp.MustCompile(`(?i)\b(\d{1,2})-Block(?:phase)?\b|\bBlock(?:phase)?-(\d{1,2})\b`)
+	courseSemesterRe = regexp.MustCompile(`(?i)\b(\d{1,2})-Sem(?:ester)?\b|\bSem(?:ester)?-(\d{1,2})\b`)
+	dashRunRe        = regexp.MustCompile(`-{2,}`)
+)
+
+func parseCourseName(name string) courseMeta {
+	m := courseMeta{Name: normalizeCourseName(name)}
+
+	// Make the string easier to match.
+	s := strings.NewReplacer("_", "-", " ", "-", ".", "-").Replace(m.Name)
+	s = dashRunRe.ReplaceAllString(s, "-")
+
+	if g := courseLetterRe.FindStringSubmatch(s); g != nil {
+		m.Program, m.Class = g[1], g[2]
+	} else if g := courseCohortRe.FindStringSubmatch(s); g != nil {
+		m.Program, m.Class = g[1], g[2]
+	} else if g := courseProgramRe.FindStringSubmatch(s); g != nil {
+		m.Program = g[1]
+	}
+	if g := courseYearRe.FindStringSubmatch(s); g != nil {
+		m.Year, _ = strconv.Atoi(g[1])
+	}
+	m.Block = firstNumber(courseBlockRe.FindStringSubmatch(s))
+	m.Semester = firstNumber(courseSemesterRe.FindStringSubmatch(s))
+	return m
+}
+
+// The first non-empty capture group as a number, 0 without a match.
+func firstNumber(groups []string) int {
+	for _, g := range groups[min(len(groups), 1):] {
+		if n, err := strconv.Atoi(g); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// Whether the program could be read from the name.
+func (m courseMeta) Known() bool {
+	return m.Program != ""
+}
+
+// Class key the course's events are aggregated under: DBWINFO-A04, DBING-01,
+// the program alone for program-wide names, or otherClass.
+func (m courseMeta) Key() string {
+	switch {
+	case m.Program == "":
+		return otherClass
+	case m.Class == "":
+		return m.Program
+	}
+	return m.Program + "-" + m.Class
+}
+
+// Metadata of a course, as found during link discovery.
+func (d scheduleData) course(name string) courseMeta {
+	if m, ok := d.Meta[name]; ok {
+		return m
+	}
+	return parseCourseName(name)
+}
+
+// Metadata shared by the courses of a class: program, class token and, when
+// the courses agree on it, the cohort year.
+func (d scheduleData) class(key string) courseMeta {
+	m := parseCourseName(key)
+	m.Year = 0
+	years := map[int]bool{}
+	for name := range d.Courses {
+		if c := d.course(name); c.Key() == key {
+			years[c.Year] = true
+			m.Year = c.Year
+		}
+	}
+	if len(years) > 1 {
+		m.Year = 0
+	}
+	return m
+}
+
+// Course names whose program could not be read, sorted.
+func (d scheduleData) unrecognized() []string {
+	seen := map[string]bool{}
+	for name := range d.Courses {
+		seen[name] = true
+	}
+	for name := range d.Failed {
+		seen[name] = true
+	}
+	var out []string
+	for name := range seen {
+		if !d.course(name).Known() {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Courses of a class in chronological order: by cohort year and block
+// number, then by first event, so "10. Block" follows "9. Block". Names
+// without a block number come after the numbered ones.
+func (d scheduleData) classBlocks(classKey string) []string {
+	var names []string
+	for name := range d.Courses {
+		if d.course(name).Key() == classKey {
+			names = append(names, name)
+		}
+	}
+	order := func(m courseMeta) int {
+		if m.Block == 0 {
+			return math.MaxInt
+		}
+		return m.Block
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := d.course(names[i]), d.course(names[j])
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		if order(a) != order(b) {
+			return order(a) < order(b)
+		}
+		fa, _ := eventSpan(d.Courses[names[i]])
+		fb, _ := eventSpan(d.Courses[names[j]])
+		if !fa.Equal(fb) {
+			return fa.Before(fb)
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// The current block of a class, the first one that has not ended at now,
+// and the block after it; empty when all blocks are over.
+func (d scheduleData) currentBlocks(classKey string, now time.Time) []string {
+	blocks := d.classBlocks(classKey)
+	for i, name := range blocks {
+		if _, last := eventSpan(d.Courses[name]); last.After(now) {
+			return blocks[i:min(i+2, len(blocks))]
+		}
+	}
+	return nil
+}
+
+// Events of the "current and next block" calendar of a class.
+func (d scheduleData) currentEvents(classKey string) []ScheduleEvent {
+	var evs []ScheduleEvent
+	for _, name := range d.Current[classKey] {
+		evs = append(evs, d.Courses[name]...)
+	}
+	return dedupeEvents(evs)
+}
+
+// Calendar file of a class's current and next block, without .ics.
+func currentFile(classKey string) string {
+	return sanitizeName(classKey) + "-current"
+}
+
+// Badges of the current and next blocks at now, by calendar file (.ics):
+// "current" once a block has started, "next" for the block after it or for
+// a current block that has not started yet.
+func blockBadges(data scheduleData, now time.Time) map[string]string {
+	out := map[string]string{}
+	for _, blocks := range data.Current {
+		for i, name := range blocks {
+			first, _ := eventSpan(data.Courses[name])
+			switch {
+			case i == 0 && !first.After(now):
+				out[sanitizeName(name)+".ics"] = "current"
+			case i == 0 || out[sanitizeName(blocks[0])+".ics"] == "current":
+				out[sanitizeName(name)+".ics"] = "next"
+			}
+		}
+	}
+	return out
+}
+
+// Start of the first and end of the last event.
+func eventSpan(events []ScheduleEvent) (first, last time.Time) {
+	for _, e := range events {
+		if first.IsZero() || e.Start.Before(first) {
+			first = e.Start
+		}
+		if e.End.After(last) {
+			last = e.End
+		}
+	}
+	return first, last
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Naming variants of the overview page's link texts, and the file-name
+// forms they turn into.
+func TestParseCourseName(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want courseMeta
+		key  string
+	}{
+		// Letter classes with numbered blocks
+		{"DBWINFO-A04 - 3. Block", courseMeta{Program: "DBWINFO", Class: "A04", Block: 3}, "DBWINFO-A04"},
+		{"DBWINFO-A04 – 4. Block", courseMeta{Program: "DBWINFO", Class: "A04", Block: 4}, "DBWINFO-A04"},
+		{"DBWINFO-A04 - 10. Block", courseMeta{Program: "DBWINFO", Class: "A04", Block: 10}, "DBWINFO-A04"},
+		{"DBWINFO-A04 - 3.Block", courseMeta{Program: "DBWINFO", Class: "A04", Block: 3}, "DBWINFO-A04"},
+		{"DBBWL-B03 - 7. Block", courseMeta{Program: "DBBWL", Class: "B03", Block: 7}, "DBBWL-B03"},
+		{"DBBWL-A03_7_7.Block", courseMeta{Program: "DBBWL", Class: "A03", Block: 7}, "DBBWL-A03"},
+		{"DBWINFO-A04_-_3_Block", courseMeta{Program: "DBWINFO", Class: "A04", Block: 3}, "DBWINFO-A04"},
+		{"DBWINFO-A04 - Block 5", courseMeta{Program: "DBWINFO", Class: "A04", Block: 5}, "DBWINFO-A04"},
+
+		// Numeric cohorts with start year and block phases
+		{"DBING-01-2024 - 4. Blockphase", courseMeta{Program: "DBING", Class: "01", Year: 2024, Block: 4}, "DBING-01"},
+		{"DBING-01-2024 – 4. Blockphase", courseMeta{Program: "DBING", Class: "01", Year: 2024, Block: 4}, "DBING-01"},
+		{"DBING-01-2024_-_4_Blockphase", courseMeta{Program: "DBING", Class: "01", Year: 2024, Block: 4}, "DBING-01"},
+		{"DBMAB-04-2023 - 1. Blockphase", courseMeta{Program: "DBMAB", Class: "04", Year: 2023, Block: 1}, "DBMAB-04"},
+		{"DBWI-05-2025 - 2. BLOCKPHASE", courseMeta{Program: "DBWI", Class: "05", Year: 2025, Block: 2}, "DBWI-05"},
+		{"DBMAB-03 - 6. Blockphase", courseMeta{Program: "DBMAB", Class: "03", Block: 6}, "DBMAB-03"},
+
+		// Program-wide and unrecognized names
+		{"DBING - 2. Semester", courseMeta{Program: "DBING", Semester: 2}, "DBING"},
+		{"DBWINFO-A04 - 3. Semester - 5. Block", courseMeta{Program: "DBWINFO", Class: "A04", Block: 5, Semester: 3}, "DBWINFO-A04"},
+		{"Sonderveranstaltung Block 2", courseMeta{Block: 2}, otherClass},
+		{"Gastvortrag", courseMeta{}, otherClass},
+	} {
+		got := parseCourseName(tc.name)
+		got.Name = ""
+		if got != tc.want || got.Key() != tc.key {
+			t.Errorf("parseCourseName(%q) = %+v key %q, want %+v key %q", tc.name, got, got.Key(), tc.want, tc.key)
+		}
+	}
+}
+
+func TestUnrecognizedCourses(t *testing.T) {
+	data := newScheduleData()
+	data.Courses["DBWINFO-A04 - 3. Block"] = nil
+	data.Courses["Sonderveranstaltung Block 2"] = nil
+	data.Failed["Gastvortrag Blockphase"] = "timeout"
+	got := data.unrecognized()
+	if len(got) != 2 || got[0] != "Gastvortrag Blockphase" || got[1] != "Sonderveranstaltung Block 2" {
+		t.Errorf("unrecognized = %q", got)
+	}
+	if m := data.class("DBWINFO-A04"); m.Program != "DBWINFO" || m.Class != "A04" {
+		t.Errorf("class meta = %+v", m)
+	}
+}
+
+func TestCurrentBlocks(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 9, 0, 0, 0, time.UTC) }
+	data := newScheduleData()
+	block := func(name string, from, to time.Time) {
+		data.Courses[name] = []ScheduleEvent{{CourseName: name, Start: from, End: from.Add(time.Hour)}, {CourseName: name, Start: to, End: to.Add(time.Hour)}}
+	}
+	block("DBWINFO-A04 - 10. Block", day(11, 2), day(11, 27))
+	block("DBWINFO-A04 - 9. Block", day(10, 5), day(10, 30))
+	block("DBWINFO-A04 - 8. Block", day(9, 7), day(9, 25))
+	block("DBWINFO-A04 - Sonderwoche", day(12, 7), day(12, 11))
+	block("DBING-01-2024 - 1. Blockphase", day(1, 5), day(1, 30))
+
+	want := "DBWINFO-A04 - 8. Block|DBWINFO-A04 - 9. Block|DBWINFO-A04 - 10. Block|DBWINFO-A04 - Sonderwoche"
+	if got := strings.Join(data.classBlocks("DBWINFO-A04"), "|"); got != want {
+		t.Errorf("classBlocks = %q, want %q", got, want)
+	}
+
+	for _, tc := range []struct {
+		now    time.Time
+		blocks string
+		badges string
+	}{
+		{day(10, 12), "DBWINFO-A04 - 9. Block|DBWINFO-A04 - 10. Block", "current 9, next 10"},
+		{day(10, 31), "DBWINFO-A04 - 10. Block|DBWINFO-A04 - Sonderwoche", "next 10"}, // between blocks
+		{day(12, 24), "", ""},
+	} {
+		data.Current = map[string][]string{}
+		if cur := data.currentBlocks("DBWINFO-A04", tc.now); len(cur) > 0 {
+			data.Current["DBWINFO-A04"] = cur
+		}
+		if got := strings.Join(data.Current["DBWINFO-A04"], "|"); got != tc.blocks {
+			t.Errorf("%v: current blocks %q, want %q", tc.now, got, tc.blocks)
+		}
+		var badges []string
+		for _, n := range []string{"9", "10"} {
+			if b := blockBadges(data, tc.now)[sanitizeName("DBWINFO-A04 - "+n+". Block")+".ics"]; b != "" {
+				badges = append(badges, b+" "+n)
+			}
+		}
+		if got := strings.Join(badges, ", "); got != tc.badges {
+			t.Errorf("%v: badges %q, want %q", tc.now, got, tc.badges)
+		}
+	}
+
+	if n := len(data.currentEvents("DBING-01")); n != 0 {
+		t.Errorf("finished class has %d current events", n)
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Spreadsheet-friendly CSV export of events: one row per event with German
+// or English headers. Files are UTF-8 with BOM so Excel detects the encoding;
+// the default delimiter is ";" because German Excel expects it.
+
+var (
+	csvLang      = getenv("ASW_CSV_LANG", "de")
+	csvDelimiter = getenv("ASW_CSV_DELIMITER", ";")
+)
+
+type csvOptions struct {
+	Lang      string // "de" or "en"
+	Delimiter rune
+}
+
+// Options from ASW_CSV_LANG and ASW_CSV_DELIMITER.
+func defaultCSVOptions() (csvOptions, error) {
+	return newCSVOptions(csvLang, csvDelimiter)
+}
+
+func newCSVOptions(lang, delimiter string) (csvOptions, error) {
+	lang = strings.ToLower(lang)
+	if _, ok := messages[lang]; !ok {
+		return csvOptions{}, fmt.Errorf("unsupported CSV language %q (use de or en)", lang)
+	}
+	if delimiter == `\t` || strings.EqualFold(delimiter, "tab") {
+		delimiter = "\t"
+	}
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size == 0 || size != len(delimiter) || r == '"' || r == '\n' || r == '\r' {
+		return csvOptions{}, fmt.Errorf("invalid CSV delimiter %q", delimiter)
+	}
+	return csvOptions{Lang: lang, Delimiter: r}, nil
+}
+
+// Status column: empty for confirmed events.
+func csvStatus(l locale, s EventStatus) string {
+	if s == StatusConfirmed || s == "" {
+		return ""
+	}
+	return l.T("ics.status." + string(s))
+}
+
+// Write events as CSV, sorted by start time.
+func writeEventsCSV(w io.Writer, events []ScheduleEvent, opt csvOptions) error {
+	sorted := append([]ScheduleEvent(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	// BOM for Excel
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = opt.Delimiter
+	cw.UseCRLF = true
+	l := locale{Lang: opt.Lang}
+	if err := cw.Write(strings.Split(l.T("csv.header"), ",")); err != nil {
+		return err
+	}
+
+	dateLayout := dateFormat
+	if opt.Lang == "en" {
+		dateLayout = "2006-01-02"
+	}
+
+	for _, e := range sorted {
+		d := e.End.Sub(e.Start)
+		week := ""
+		if e.Week > 0 {
+			week = strconv.Itoa(e.Week)
+		}
+
+		rec := []string{
+			e.Start.Format(dateLayout),
+			l.weekdayLong(e.Start.Weekday()),
+			e.Start.Format("15:04"),
+			e.End.Format("15:04"),
+			fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60),
+			csvModule(e),
+			e.EventType,
+			csvRoom(e),
+			e.Instructor,
+			e.CourseName,
+			extractClassKey(e.CourseName),
+			week,
+			csvStatus(l, e.Status),
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvModule(e ScheduleEvent) string {
+	if e.Module != "" {
+		return e.Module
+	}
+	return e.Summary
+}
+
+func csvRoom(e ScheduleEvent) string {
+	switch {
+	case e.IsOnline:
+		return "Online"
+	case e.Room != "":
+		return roomLabel(e)
+	}
+	return e.Location
+}
+
+// Write <file>.csv next to every published calendar in dir.
+func writeCSVExport(dir string, data scheduleData) error {
+	opt, err := defaultCSVOptions()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range publishedCalendars(data) {
+		if err := writeCSVFile(filepath.Join(dir, c.File+".csv"), c.Events, opt); err != nil {
+			return fmt.Errorf("%s: %w", c.File, err)
+		}
+	}
+	return nil
+}
+
+func writeCSVFile(path string, events []ScheduleEvent, opt csvOptions) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeEventsCSV(f, events, opt); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// csvFilter selects events for the csv subcommand. Empty fields match all.
+type csvFilter struct {
+	Class     string
+	Course    string
+	Module    string // substring, case-insensitive
+	Type      string // substring, case-insensitive
+	From, To  time.Time
+	Cancelled bool
+}
+
+func (f csvFilter) match(e ScheduleEvent) bool {
+	if f.Class != "" && extractClassKey(e.CourseName) != f.Class {
+		return false
+	}
+	if f.Course != "" && e.CourseName != f.Course {
+		return false
+	}
+	if f.Module != "" && !strings.Contains(foldGerman(csvModule(e)), foldGerman(f.Module)) {
+		return false
+	}
+	if f.Type != "" && !strings.Contains(foldGerman(e.EventType), foldGerman(f.Type)) {
+		return false
+	}
+	if !f.From.IsZero() && e.Start.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !e.Start.Before(f.To) {
+		return false
+	}
+	if !f.Cancelled && e.Status == StatusCancelled {
+		return false
+	}
+	return true
+}
+
+// `csv` subcommand: parse the schedules and print the selected events as CSV.
+// Progress logging goes to stderr, so the output can be piped.
+//
+//	asw-parser csv [-class KEY] [-course NAME] [-module TEXT] [-type TEXT]
+//	               [-from DATE] [-to DATE] [-cancelled] [-lang de|en] [-delimiter ;] [-o FILE]
+func runCSVCommand(args []string) error {
+	fs := flag.NewFlagSet("csv", flag.ContinueOnError)
+	class := fs.String("class", "", "only this class key, e.g. DBWINFO-A04")
+	course := fs.String("course", "", "only this course, e.g. \"DBWINFO-A04 - 3. Block\"")
+	module := fs.String("module", "", "only modules containing this text")
+	typ := fs.String("type", "", "only event types containing this text, e.g. Klausur")
+	from := fs.String("from", "", "only events on or after this date (dd.mm.yyyy)")
+	to := fs.String("to", "", "only events on or before this date (dd.mm.yyyy)")
+	cancelled := fs.Bool("cancelled", false, "include cancelled events")
+	lang := fs.String("lang", csvLang, "header language: de or en")
+	delimiter := fs.String("delimiter", csvDelimiter, "field delimiter, e.g. ; or , or tab")
+	out := fs.String("o", "", "write to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opt, err := newCSVOptions(*lang, *delimiter)
+	if err != nil {
+		return err
+	}
+
+	filter := csvFilter{Class: *class, Course: *course, Module: *module, Type: *typ, Cancelled: *cancelled}
+	loc, err := time.LoadLocation(tzID)
+	if err != nil {
+		return err
+	}
+	if *from != "" {
+		if filter.From, err = time.ParseInLocation(dateFormat, *from, loc); err != nil {
+			return fmt.Errorf("-from: %w", err)
+		}
+	}
+	if *to != "" {
+		t, err := time.ParseInLocation(dateFormat, *to, loc)
+		if err != nil {
+			return fmt.Errorf("-to: %w", err)
+		}
+		filter.To = t.AddDate(0, 0, 1)
+	}
+
+	data, err := loadSchedule()
+	if err != nil {
+		return err
+	}
+
+	// Class calendars are deduplicated; courses only matter for -course.
+	source := data.Classes
+	if *course != "" {
+		source = data.Courses
+	}
+	var events []ScheduleEvent
+	for _, k := range eventKeys(source) {
+		for _, e := range source[k] {
+			if filter.match(e) {
+				events = append(events, e)
+			}
+		}
+	}
+
+	if *out != "" {
+		return writeCSVFile(*out, events, opt)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if err := writeEventsCSV(w, events, opt); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteEventsCSV(t *testing.T) {
+	start := time.Date(2025, 12, 9, 9, 0, 0, 0, time.UTC)
+	events := []ScheduleEvent{
+		{CourseName: "DBWINFO-A04 - 3. Block", Summary: "Marketing", Start: start.Add(26 * time.Hour), End: start.Add(27 * time.Hour), Status: StatusCancelled, Location: "EXT: Online", IsOnline: true},
+		{CourseName: "DBWINFO-A04 - 3. Block", Summary: "Rechnungswesen (Klausur)", Module: "Rechnungswesen", EventType: "Klausur", Site: "NK", Room: "1.12", Instructor: "Dr. Schulz; Fr. Weber", Week: 7, Start: start, End: start.Add(195 * time.Minute)},
+	}
+
+	opt, err := newCSVOptions("en", "tab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := writeEventsCSV(&b, events, opt); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\ufeffDate\tWeekday\tStart\tEnd\tDuration\tModule\tType\tRoom\tInstructor\tCourse\tClass\tWeek\tStatus\r\n" +
+		"2025-12-09\tTuesday\t09:00\t12:15\t3:15\tRechnungswesen\tKlausur\tNK 1.12\tDr. Schulz; Fr. Weber\tDBWINFO-A04 - 3. Block\tDBWINFO-A04\t7\t\r\n" +
+		"2025-12-10\tWednesday\t11:00\t12:00\t1:00\tMarketing\t\tOnline\t\tDBWINFO-A04 - 3. Block\tDBWINFO-A04\t\tcancelled\r\n"
+	if b.String() != want {
+		t.Errorf("csv =\n%q\nwant\n%q", b.String(), want)
+	}
+
+	for _, bad := range [][2]string{{"fr", ";"}, {"de", `"`}, {"de", ";;"}} {
+		if _, err := newCSVOptions(bad[0], bad[1]); err == nil {
+			t.Errorf("newCSVOptions(%q, %q) accepted", bad[0], bad[1])
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// Keywords that mark an event as exam-like. Matched as whole words against
+	// the event type, module and footnotes, with umlauts folded (ä -> ae).
+	examKeywords = splitList(getenv("ASW_EXAM_KEYWORDS",
+		"Klausur,Nachklausur,Prüfung,Nachprüfung,mündliche Prüfung,Präsentation,Abgabe,Kolloquium,Verteidigung,Testat"))
+
+	// Default reminder lead times for exams (the "exam" rule in reminders.go).
+	examReminders = getenv("ASW_EXAM_REMINDERS", "7d,1d")
+
+	examRe = compileExamKeywords(examKeywords)
+)
+
+func compileExamKeywords(keywords []string) *regexp.Regexp {
+	parts := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		parts = append(parts, strings.Join(strings.Fields(regexp.QuoteMeta(foldGerman(k))), `\s+`))
+	}
+	if len(parts) == 0 {
+		// Matches nothing.
+		return regexp.MustCompile(`$^`)
+	}
+	return regexp.MustCompile(`\b(` + strings.Join(parts, "|") + `)\b`)
+}
+
+// Lowercase and transliterate umlauts so "Prüfung" and "Pruefung" compare equal
+// and \b word boundaries work on plain ASCII.
+func foldGerman(s string) string {
+	r := strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+	return r.Replace(strings.ToLower(s))
+}
+
+// Report whether an event is an exam or assessment.
+// The module line is included because sked sometimes lists "Klausur IBL III"
+// as module with a generic type.
+func isExamEvent(e ScheduleEvent) bool {
+	for _, s := range append([]string{e.EventType, e.Module}, e.Notes...) {
+		if s != "" && examRe.MatchString(foldGerman(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+func filterExams(events []ScheduleEvent) []ScheduleEvent {
+	var out []ScheduleEvent
+	for _, e := range events {
+		if isExamEvent(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Write <class>-exams.ics with reminders ahead of every exam
+// (the "exam" rule of the reminder policy, 7 and 1 days by default).
+func generateExamICS(classKey string, exams []ScheduleEvent) error {
+	return writeICS(classKey+" Exams", icsText("ics.exams", classKey), sanitizeName(classKey)+"-exams", exams, reminders.forClass(classKey))
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// JSON export of the parsed data for dashboards and bots: ics_files/index.json
+// lists every published calendar, ics_files/<file>.json holds its events.
+// The format is described by the JSON Schemas in ics_files/schema/ and
+// versioned with jsonExportVersion; incompatible changes bump the version.
+// Version 2 added the "current" calendar kind.
+
+const jsonExportVersion = 2
+
+// calendarEntry is one published calendar, independent of its file format.
+type calendarEntry struct {
+	Kind   string // class, current, course, exams, module, room, lecturer
+	Name   string
+	File   string // relative to the ICS folder, without extension
+	Class  string // class key, "" for rooms and lecturers
+	Events []ScheduleEvent
+}
+
+// Order of the kinds in the index.
+var calendarKinds = []string{"class", "current", "course", "exams", "module", "room", "lecturer"}
+
+// All calendars the writers produce for data, in index order.
+func publishedCalendars(data scheduleData) []calendarEntry {
+	var out []calendarEntry
+
+	for _, classKey := range eventKeys(data.Classes) {
+		evs := data.Classes[classKey]
+		out = append(out, calendarEntry{"class", classKey, sanitizeName(classKey), classKey, evs})
+	}
+	for _, classKey := range eventKeys(data.Classes) {
+		if evs := data.currentEvents(classKey); len(evs) > 0 {
+			out = append(out, calendarEntry{"current", icsText("ics.current", classKey), currentFile(classKey), classKey, evs})
+		}
+	}
+	for _, courseName := range eventKeys(data.Courses) {
+		classKey := extractClassKey(courseName)
+		out = append(out, calendarEntry{"course", courseName, sanitizeName(courseName), classKey, data.Courses[courseName]})
+	}
+	for _, classKey := range eventKeys(data.Classes) {
+		if exams := filterExams(data.Classes[classKey]); len(exams) > 0 {
+			out = append(out, calendarEntry{"exams", icsText("ics.exams", classKey), sanitizeName(classKey) + "-exams", classKey, exams})
+		}
+	}
+	for _, classKey := range eventKeys(data.Classes) {
+		for _, mc := range moduleGroups(data.Classes[classKey]) {
+			out = append(out, calendarEntry{"module", classKey + " " + mc.Name, moduleFile(classKey, mc.Slug), classKey, mc.Events})
+		}
+	}
+	for _, rc := range roomCalendars(data) {
+		out = append(out, calendarEntry{"room", icsText("ics.room", rc.Label), rc.File, "", rc.Events})
+	}
+	for _, rc := range lecturerCalendars(data) {
+		out = append(out, calendarEntry{"lecturer", rc.Label, rc.File, "", rc.Events})
+	}
+
+	return out
+}
+
+// Stable event ID: derived from what identifies a slot in the source
+// (course, start, module or event type, group), not from its position in a
+// file, so it survives re-runs and unrelated schedule changes. Only
+// untranslated source fields are used, not the Summary, which carries the
+// localized "Cancelled:" prefix. Status changes and room changes keep the
+// ID; a moved event gets a new one.
+func eventID(e ScheduleEvent) string {
+	what := e.Module
+	if what == "" {
+		what = e.EventType
+	}
+	sum := sha1.Sum([]byte(e.CourseName + "|" + e.Start.UTC().Format(time.RFC3339) + "|" + what + "|" + e.Group))
+	return hex.EncodeToString(sum[:8])
+}
+
+type jsonIndex struct {
+	Schema    string             `json:"$schema"`
+	Version   int                `json:"version"`
+	Generated string             `json:"generated"`
+	Timezone  string             `json:"timezone"`
+	Classes   []jsonClass        `json:"classes"`
+	Calendars []jsonCalendarInfo `json:"calendars"`
+}
+
+type jsonClass struct {
+	Key     string   `json:"key"`
+	Courses []string `json:"courses"`
+}
+
+type jsonCalendarInfo struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Class  string `json:"class,omitempty"`
+	ICS    string `json:"ics"`
+	JSON   string `json:"json"`
+	JCal   string `json:"jcal"`
+	XCal   string `json:"xcal"`
+	Events int    `json:"events"`
+	First  string `json:"first,omitempty"`
+	Last   string `json:"last,omitempty"`
+}
+
+type jsonCalendar struct {
+	Schema  string      `json:"$schema"`
+	Version int         `json:"version"`
+	ID      string      `json:"id"`
+	Kind    string      `json:"kind"`
+	Name    string      `json:"name"`
+	Class   string      `json:"class,omitempty"`
+	Events  []jsonEvent `json:"events"`
+}
+
+type jsonEvent struct {
+	ID           string     `json:"id"`
+	Course       string     `json:"course"`
+	Summary      string     `json:"summary"`
+	Description  string     `json:"description,omitempty"`
+	Location     string     `json:"location,omitempty"`
+	Start        string     `json:"start"`
+	End          string     `json:"end"`
+	Status       string     `json:"status"`
+	StatusNote   string     `json:"statusNote,omitempty"`
+	Week         *jsonWeek  `json:"week,omitempty"`
+	Type         string     `json:"type,omitempty"`
+	Module       string     `json:"module,omitempty"`
+	ModuleSlug   string     `json:"moduleSlug,omitempty"`
+	Group        string     `json:"group,omitempty"`
+	Site         string     `json:"site,omitempty"`
+	Room         string     `json:"room,omitempty"`
+	RoomKey      string     `json:"roomKey,omitempty"`
+	Instructor   string     `json:"instructor,omitempty"`
+	Instructors  []string   `json:"instructors,omitempty"`
+	Online       bool       `json:"online"`
+	Exam         bool       `json:"exam"`
+	Notes        []string   `json:"notes,omitempty"`
+	FootnoteRefs []string   `json:"footnoteRefs,omitempty"`
+	Extra        []string   `json:"extra,omitempty"`
+	Source       jsonSource `json:"source"`
+}
+
+type jsonWeek struct {
+	Number int    `json:"number"`
+	Label  string `json:"label,omitempty"`
+	Start  string `json:"start,omitempty"`
+	End    string `json:"end,omitempty"`
+}
+
+type jsonSource struct {
+	URL    string `json:"url,omitempty"`
+	CellID string `json:"cellId,omitempty"`
+}
+
+func toJSONEvent(e ScheduleEvent) jsonEvent {
+	status := e.Status
+	if status == "" {
+		status = StatusConfirmed
+	}
+
+	je := jsonEvent{
+		ID:           eventID(e),
+		Course:       e.CourseName,
+		Summary:      e.Summary,
+		Description:  e.Description,
+		Location:     e.Location,
+		Start:        e.Start.Format(time.RFC3339),
+		End:          e.End.Format(time.RFC3339),
+		Status:       string(status),
+		StatusNote:   e.StatusNote,
+		Type:         e.EventType,
+		Module:       e.Module,
+		Group:        e.Group,
+		Site:         e.Site,
+		Room:         e.Room,
+		RoomKey:      roomKey(e),
+		Instructor:   e.Instructor,
+		Instructors:  instructorNames(e),
+		Online:       e.IsOnline,
+		Exam:         isExamEvent(e),
+		Notes:        e.Notes,
+		FootnoteRefs: e.FootnoteRefs,
+		Extra:        e.Extra,
+		Source:       jsonSource{URL: e.SourceURL, CellID: e.CellID},
+	}
+	if e.Module != "" {
+		je.ModuleSlug = moduleSlug(e.Module, moduleAliases)
+	}
+	if e.Week > 0 {
+		je.Week = &jsonWeek{Number: e.Week, Label: e.WeekLabel}
+		if !e.WeekStart.IsZero() {
+			je.Week.Start = e.WeekStart.Format("2006-01-02")
+			je.Week.End = e.WeekEnd.Format("2006-01-02")
+		}
+	}
+	return je
+}
+
+// Events in deterministic order: start, end, then ID. Events sharing an ID
+// (two parallel rooms for the same group) get a numeric suffix.
+func toJSONEvents(events []ScheduleEvent) []jsonEvent {
+	out := make([]jsonEvent, 0, len(events))
+	for _, e := range events {
+		out = append(out, toJSONEvent(e))
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Start != out[j].Start {
+			return out[i].Start < out[j].Start
+		}
+		if out[i].End != out[j].End {
+			return out[i].End < out[j].End
+		}
+		return out[i].ID < out[j].ID
+	})
+
+	seen := map[string]int{}
+	for i := range out {
+		id := out[i].ID
+		seen[id]++
+		if n := seen[id]; n > 1 {
+			out[i].ID = id + "-" + strconv.Itoa(n)
+		}
+	}
+	return out
+}
+
+// Write index.json, one <file>.json per calendar and the schemas into dir.
+func writeJSONExport(dir string, data scheduleData, now time.Time) error {
+	calendars := publishedCalendars(data)
+
+	index := jsonIndex{
+		Schema:    "schema/index.v" + strconv.Itoa(jsonExportVersion) + ".json",
+		Version:   jsonExportVersion,
+		Generated: now.Format(time.RFC3339),
+		Timezone:  tzID,
+		Classes:   []jsonClass{},
+		Calendars: []jsonCalendarInfo{},
+	}
+
+	classCourses := map[string][]string{}
+	for _, courseName := range eventKeys(data.Courses) {
+		k := extractClassKey(courseName)
+		classCourses[k] = append(classCourses[k], courseName)
+	}
+	for _, classKey := range eventKeys(data.Classes) {
+		courses := classCourses[classKey]
+		if courses == nil {
+			courses = []string{}
+		}
+		index.Classes = append(index.Classes, jsonClass{Key: classKey, Courses: courses})
+	}
+
+	calSchema := "schema/calendar.v" + strconv.Itoa(jsonExportVersion) + ".json"
+	for _, c := range calendars {
+		events := toJSONEvents(c.Events)
+
+		// Schema path relative to the calendar file, which may sit in a subfolder.
+		rel, err := filepath.Rel(filepath.Dir(filepath.Join(dir, c.File)), filepath.Join(dir, calSchema))
+		if err != nil {
+			return err
+		}
+		cal := jsonCalendar{
+			Schema:  filepath.ToSlash(rel),
+			Version: jsonExportVersion,
+			ID:      c.File,
+			Kind:    c.Kind,
+			Name:    c.Name,
+			Class:   c.Class,
+			Events:  events,
+		}
+		if err := writeJSONFile(filepath.Join(dir, c.File+".json"), cal); err != nil {
+			return fmt.Errorf("%s: %w", c.File, err)
+		}
+
+		info := jsonCalendarInfo{
+			ID:     c.File,
+			Kind:   c.Kind,
+			Name:   c.Name,
+			Class:  c.Class,
+			ICS:    c.File + ".ics",
+			JSON:   c.File + ".json",
+			JCal:   c.File + ".jcal.json",
+			XCal:   c.File + ".xcs",
+			Events: len(events),
+		}
+		if len(events) > 0 {
+			info.First = events[0].Start
+			info.Last = events[len(events)-1].End
+		}
+		index.Calendars = append(index.Calendars, info)
+	}
+
+	kindOrder := map[string]int{}
+	for i, k := range calendarKinds {
+		kindOrder[k] = i
+	}
+	sort.SliceStable(index.Calendars, func(i, j int) bool {
+		a, b := index.Calendars[i], index.Calendars[j]
+		if a.Kind != b.Kind {
+			return kindOrder[a.Kind] < kindOrder[b.Kind]
+		}
+		return a.ID < b.ID
+	})
+
+	if err := writeJSONFile(filepath.Join(dir, "index.json"), index); err != nil {
+		return err
+	}
+
+	schemaDir := filepath.Join(dir, "schema")
+	if err := os.MkdirAll(schemaDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, index.Schema), []byte(indexSchema), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, calSchema), []byte(calendarSchema), 0644)
+}
+
+func writeJSONFile(path string, v any) error {
+	js, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(js, '\n'), 0644)
+}
+
+const indexSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "index.v2.json",
+  "title": "ASW calendar index",
+  "description": "All calendars published by the ASW schedule exporter.",
+  "type": "object",
+  "required": ["version", "generated", "timezone", "classes", "calendars"],
+  "properties": {
+    "$schema": {"type": "string"},
+    "version": {"const": 2},
+    "generated": {"type": "string", "format": "date-time"},
+    "timezone": {"type": "string", "description": "IANA timezone of all event times"},
+    "classes": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["key", "courses"],
+        "properties": {
+          "key": {"type": "string", "description": "Class key, e.g. DBWINFO-A04"},
+          "courses": {"type": "array", "items": {"type": "string"}, "description": "Course (block) names of the class"}
+        },
+        "additionalProperties": false
+      }
+    },
+    "calendars": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["id", "kind", "name", "ics", "json", "jcal", "xcal", "events"],
+        "properties": {
+          "id": {"type": "string", "description": "Stable calendar ID, the file path without extension"},
+          "kind": {"enum": ["class", "current", "course", "exams", "module", "room", "lecturer"]},
+          "name": {"type": "string"},
+          "class": {"type": "string"},
+          "ics": {"type": "string", "description": "Path of the .ics file, relative to the index"},
+          "json": {"type": "string", "description": "Path of the calendar JSON file, relative to the index"},
+          "jcal": {"type": "string", "description": "Path of the jCal (RFC 7265) file, relative to the index"},
+          "xcal": {"type": "string", "description": "Path of the xCal (RFC 6321) file, relative to the index"},
+          "events": {"type": "integer", "minimum": 0},
+          "first": {"type": "string", "format": "date-time"},
+          "last": {"type": "string", "format": "date-time"}
+        },
+        "additionalProperties": false
+      }
+    }
+  },
+  "additionalProperties": false
+}
+`
+
+const calendarSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "calendar.v2.json",
+  "title": "ASW calendar",
+  "description": "Events of one calendar published by the ASW schedule exporter, sorted by start, end and id.",
+  "type": "object",
+  "required": ["version", "id", "kind", "name", "events"],
+  "properties": {
+    "$schema": {"type": "string"},
+    "version": {"const": 2},
+    "id": {"type": "string"},
+    "kind": {"enum": ["class", "current", "course", "exams", "module", "room", "lecturer"]},
+    "name": {"type": "string"},
+    "class": {"type": "string"},
+    "events": {"type": "array", "items": {"$ref": "#/$defs/event"}}
+  },
+  "additionalProperties": false,
+  "$defs": {
+    "event": {
+      "type": "object",
+      "required": ["id", "course", "summary", "start", "end", "status", "online", "exam", "source"],
+      "properties": {
+        "id": {"type": "string", "description": "Stable across runs; changes when the event moves to another time"},
+        "course": {"type": "string"},
+        "summary": {"type": "string"},
+        "description": {"type": "string"},
+        "location": {"type": "string"},
+        "start": {"type": "string", "format": "date-time"},
+        "end": {"type": "string", "format": "date-time"},
+        "status": {"enum": ["CONFIRMED", "TENTATIVE", "CANCELLED"]},
+        "statusNote": {"type": "string"},
+        "week": {
+          "type": "object",
+          "required": ["number"],
+          "properties": {
+            "number": {"type": "integer", "minimum": 1},
+            "label": {"type": "string"},
+            "start": {"type": "string", "format": "date"},
+            "end": {"type": "string", "format": "date"}
+          },
+          "additionalProperties": false
+        },
+        "type": {"type": "string", "description": "Event type as written in the source, e.g. Vorlesung"},
+        "module": {"type": "string"},
+        "moduleSlug": {"type": "string"},
+        "group": {"type": "string"},
+        "site": {"type": "string"},
+        "room": {"type": "string"},
+        "roomKey": {"type": "string"},
+        "instructor": {"type": "string"},
+        "instructors": {"type": "array", "items": {"type": "string"}},
+        "online": {"type": "boolean"},
+        "exam": {"type": "boolean"},
+        "notes": {"type": "array", "items": {"type": "string"}},
+        "footnoteRefs": {"type": "array", "items": {"type": "string"}},
+        "extra": {"type": "array", "items": {"type": "string"}},
+        "source": {
+          "type": "object",
+          "properties": {
+            "url": {"type": "string"},
+            "cellId": {"type": "string"}
+          },
+          "additionalProperties": false
+        }
+      },
+      "additionalProperties": false
+    }
+  }
+}
+`
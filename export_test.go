@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestJSONEventIDs(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 12, 8, h, 0, 0, 0, time.UTC) }
+	lecture := ScheduleEvent{CourseName: "DBWINFO-A04 - 3. Block", Summary: "IBL III (Vorlesung)", Module: "IBL III", Start: at(9), End: at(10)}
+
+	// Status and room changes keep the ID, a new time does not.
+	cancelled := lecture
+	cancelled.Summary = "Cancelled: " + lecture.Summary
+	cancelled.Status = StatusCancelled
+	cancelled.Room = "2.05"
+	if eventID(cancelled) != eventID(lecture) {
+		t.Errorf("ID changed with status/room: %s != %s", eventID(cancelled), eventID(lecture))
+	}
+	moved := lecture
+	moved.Start, moved.End = at(11), at(12)
+	if eventID(moved) == eventID(lecture) {
+		t.Errorf("moved event kept ID %s", eventID(moved))
+	}
+
+	// Without a module the ID does not depend on the cancelled prefix of
+	// the summary, nor on its language.
+	exam := ScheduleEvent{CourseName: lecture.CourseName, EventType: "Klausur", Start: at(13), End: at(15), Status: StatusConfirmed}
+	exam.Summary = summarizeEvent(exam)
+	examCancelled := exam
+	examCancelled.Status = StatusCancelled
+	examCancelled.Summary = summarizeEvent(examCancelled)
+	if examCancelled.Summary == exam.Summary || eventID(examCancelled) != eventID(exam) {
+		t.Errorf("module-less event changed ID when cancelled: %q %s, %q %s", exam.Summary, eventID(exam), examCancelled.Summary, eventID(examCancelled))
+	}
+
+	// Same slot in two rooms: unique IDs, deterministic order.
+	other := lecture
+	other.Room = "2.06"
+	events := toJSONEvents([]ScheduleEvent{moved, lecture, other})
+	if events[0].ID != eventID(lecture) || events[1].ID != eventID(lecture)+"-2" || events[2].ID != eventID(moved) {
+		t.Errorf("unexpected IDs/order: %s, %s, %s", events[0].ID, events[1].ID, events[2].ID)
+	}
+}
+
+func TestEventUIDs(t *testing.T) {
+	start := time.Date(2025, 12, 8, 9, 0, 0, 0, time.UTC)
+	lecture := ScheduleEvent{CourseName: "DBWINFO-A04 - 4. Block", Summary: "Marketing", Start: start, End: start.Add(time.Hour)}
+
+	// The UID does not depend on the position, which shifts when the
+	// current-block calendar drops the previous block.
+	want := "DBWINFO-A04_Current-" + eventID(lecture)
+	if got := eventUIDs("DBWINFO-A04_Current")(0, lecture); got != want {
+		t.Errorf("uid = %s, want %s", got, want)
+	}
+	uid := eventUIDs("DBWINFO-A04_Current")
+	if got := uid(7, lecture); got != want {
+		t.Errorf("uid at 7 = %s, want %s", got, want)
+	}
+	if got := uid(8, lecture); got != want+"-2" {
+		t.Errorf("repeated uid = %s, want %s-2", got, want)
+	}
+}
+
+func TestJSONSchemasAreValidJSON(t *testing.T) {
+	for name, s := range map[string]string{"index": indexSchema, "calendar": calendarSchema} {
+		var v struct {
+			ID         string `json:"$id"`
+			Properties struct {
+				Version struct {
+					Const int `json:"const"`
+				} `json:"version"`
+			} `json:"properties"`
+		}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.Errorf("%s schema: %v", name, err)
+		}
+		// The schema must follow jsonExportVersion, see export.go.
+		if v.ID != name+".v"+strconv.Itoa(jsonExportVersion)+".json" || v.Properties.Version.Const != jsonExportVersion {
+			t.Errorf("%s schema: $id %q, version %d, want version %d", name, v.ID, v.Properties.Version.Const, jsonExportVersion)
+		}
+	}
+}
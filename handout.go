@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// QR codes of the subscription links (qr/<calendar>.https.svg and
+// .webcal.svg, mirroring ics_files/) and the printable per-class handouts
+// (handout-<class>.html) that show them. The codes need the absolute URL
+// the site is published under, so they are only made when ASW_SITE_URL is set.
+
+var siteURL = getenv("ASW_SITE_URL", "")
+
+// The parsed ASW_SITE_URL.
+func siteBase() (*url.URL, error) {
+	base, err := url.Parse(siteURL)
+	if err != nil || (base.Scheme != "https" && base.Scheme != "http") || base.Host == "" {
+		return nil, fmt.Errorf("ASW_SITE_URL: %q is not an absolute http(s) URL", siteURL)
+	}
+	return base, nil
+}
+
+// Public URL of a file in ics_files/; name uses forward slashes.
+func calendarURL(base *url.URL, name string) string {
+	segs := strings.Split(name, "/")
+	for i, s := range segs {
+		segs[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(base.String(), "/") + "/ics_files/" + strings.Join(segs, "/")
+}
+
+// The webcal:// form of a calendar URL, which calendar apps open as a
+// subscription.
+func webcalURL(link string) string {
+	return "webcal://" + strings.TrimPrefix(strings.TrimPrefix(link, "https://"), "http://")
+}
+
+// Write both QR codes for every published calendar, including the
+// reminder variants.
+func writeQRCodes() error {
+	if siteURL == "" {
+		log.Printf("ASW_SITE_URL not set, skipping QR codes and handouts")
+		return nil
+	}
+	base, err := siteBase()
+	if err != nil {
+		return err
+	}
+
+	qrDir := filepath.Join(publicDir, "qr")
+	return filepath.WalkDir(publicICSDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".ics" {
+			return err
+		}
+		rel, err := filepath.Rel(publicICSDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		https := calendarURL(base, rel)
+		links := map[string]string{
+			"https":  https,
+			"webcal": webcalURL(https),
+		}
+		for kind, link := range links {
+			q, err := encodeQR(link)
+			if err != nil {
+				return fmt.Errorf("%s: %w", rel, err)
+			}
+			target := filepath.Join(qrDir, filepath.FromSlash(qrFile(rel, kind)))
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(target, []byte(q.svg()), 0644); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// File of a QR code below qr/, e.g. DBWINFO-A04.https.svg for DBWINFO-A04.ics.
+func qrFile(name, kind string) string {
+	return strings.TrimSuffix(name, ".ics") + "." + kind + ".svg"
+}
+
+func hasPublishedQR(name string) bool {
+	_, err := os.Stat(filepath.Join(publicDir, "qr", filepath.FromSlash(qrFile(name, "https"))))
+	return err == nil
+}
+
+// File name of a class handout page, relative to the language folder.
+func handoutFile(classKey string) string {
+	return "handout-" + sanitizeName(classKey) + ".html"
+}
+
+// Write a handout for every class into the language folder. Nothing is
+// written without QR codes.
+func renderHandouts(data scheduleData, l locale) error {
+	for _, classKey := range eventKeys(data.Classes) {
+		name := sanitizeName(classKey) + ".ics"
+		if !hasPublishedQR(name) {
+			continue
+		}
+		if err := renderHandoutPage(l, classKey, data.Classes[classKey]); err != nil {
+			return fmt.Errorf("handout %s: %w", classKey, err)
+		}
+	}
+	return nil
+}
+
+// Data of a class handout (pages/handout.html).
+type handoutPage struct {
+	pageData
+	Cards   []handoutCard // class, current block and exam calendars
+	Modules []handoutCard
+}
+
+// One calendar with its webcal and HTTPS codes (partials/handout-card.html).
+type handoutCard struct {
+	locale
+	Label string
+	QR    string // qr/ path without the .https.svg/.webcal.svg suffix
+	Link  string // public URL, or the file name without ASW_SITE_URL
+}
+
+func newHandoutCard(l locale, name, label string) handoutCard {
+	link := name
+	if base, err := siteBase(); err == nil {
+		link = calendarURL(base, name)
+	}
+	return handoutCard{locale: l, Label: label, QR: strings.TrimSuffix(name, ".ics"), Link: link}
+}
+
+func renderHandoutPage(l locale, classKey string, events []ScheduleEvent) error {
+	page := handoutPage{pageData: l.newPage(handoutFile(classKey), l.T("page.handout", classKey), l.T("page.handout.sub"))}
+	base := sanitizeName(classKey)
+
+	page.Cards = append(page.Cards, newHandoutCard(l, base+".ics", l.T("ho.class")+" "+classKey))
+	if cur := currentFile(classKey) + ".ics"; hasPublishedQR(cur) {
+		page.Cards = append(page.Cards, newHandoutCard(l, cur, l.T("ho.current")+" "+classKey))
+	}
+	if exams := base + "-exams.ics"; hasPublishedQR(exams) {
+		page.Cards = append(page.Cards, newHandoutCard(l, exams, l.T("ho.exams")+" "+classKey))
+	}
+
+	for _, m := range moduleGroups(events) {
+		if name := moduleFile(classKey, m.Slug) + ".ics"; hasPublishedQR(name) {
+			page.Modules = append(page.Modules, newHandoutCard(l, name, m.Name))
+		}
+	}
+
+	return renderThemePage("handout.html", page)
+}
@@ -0,0 +1,578 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Localization: message catalogs for the site and for the text written into
+// the calendars. The site is rendered once per language into public/<lang>/;
+// the calendars, JSON, CSV and other data files are shared and stay in the
+// public root, where small redirect pages forward old links to a language.
+
+var (
+	siteLangs = getenv("ASW_SITE_LANGS", "de,en") // first one is the default
+	icsLang   = getenv("ASW_ICS_LANG", "en")
+)
+
+// Catalog keys: ics.* calendar text, js.* strings used by the theme scripts,
+// everything else site text. Values are fmt formats where they take arguments.
+var messages = map[string]map[string]string{
+	"en": {
+		"lang.name": "English",
+
+		"ics.title":            "ASW Schedule %s",
+		"ics.exams":            "%s Exams",
+		"ics.current":          "%s Current and next block",
+		"ics.room":             "Room %s",
+		"ics.event":            "ASW event",
+		"ics.cancelled":        "Cancelled: %s",
+		"ics.course":           "Course: %s",
+		"ics.week":             "Week: %d (%s - %s)",
+		"ics.type":             "Type: %s",
+		"ics.module":           "Module/Group: %s",
+		"ics.instructor":       "Instructor: %s",
+		"ics.location":         "Location: %s",
+		"ics.status":           "Status: %s (%s)",
+		"ics.status.TENTATIVE": "tentative",
+		"ics.status.CANCELLED": "cancelled",
+		"ics.note":             "Note: %s",
+		"ics.reminder.exam":    "Upcoming exam: %s",
+		"ics.reminder.travel":  "Time to travel to %s: %s",
+		"weekdays":             "Mon,Tue,Wed,Thu,Fri,Sat,Sun",
+		"weekdays.long":        "Monday,Tuesday,Wednesday,Thursday,Friday,Saturday,Sunday",
+		"csv.header":           "Date,Weekday,Start,End,Duration,Module,Type,Room,Instructor,Course,Class,Week,Status",
+		"nav.individual":       "Show individual calendars",
+		"nav.index":            "Back to class calendars",
+		"nav.exams":            "Exam calendars",
+		"nav.all":              "All calendars",
+		"nav.rooms":            "Rooms",
+		"nav.occupancy":        "Free rooms",
+		"nav.lecturers":        "Lecturers",
+		"nav.stats":            "Statistics",
+		"nav.conflicts":        "Conflicts",
+		"nav.source":           "Source page",
+		"footer":               "Updated by GitHub Actions on schedule.",
+		"search.placeholder":   "Search module, lecturer, room, class or block…",
+		"info.title":           "Quick setup",
+		"info.body.html":       "Use <b>Subscribe</b> for webcal subscription (best supported on Apple). For Google Calendar on Android/Windows, <a href='help-google.html'>follow this guide</a>. Alternatively use <b>Copy URL</b> to add the feed manually or <b>Download file</b> for a one-time import.",
+		"info.reminders":       "Include reminders before lectures, on-site events and exams. Leave this off if your calendar app adds its own alerts.",
+		"row.subscribe":        "Subscribe",
+		"row.copy":             "Copy URL",
+		"row.download":         "Download file",
+		"row.preview":          "Preview",
+		"row.timetable":        "Timetable",
+		"row.csv":              "CSV",
+		"row.event":            "1 event",
+		"row.events":           "%d events",
+		"row.changed":          "changed %s",
+		"row.stale":            "Source not fetched successfully since %s",
+		"row.staleNever":       "Source never fetched successfully",
+		"header.asof":          "Data as of %s",
+		"row.qr":               "QR code",
+		"row.handout":          "Handout",
+		"row.current":          "Current block",
+		"row.next":             "Next block",
+		"row.changes":          "Changes",
+		"qr.https":             "Android / Google Calendar (HTTPS)",
+		"qr.webcal":            "iPhone / Mac (webcal)",
+		"qr.alt":               "QR code for %s",
+		"row.modules":          "Modules",
+		"cal.nofiles":          "No files",
+		"cal.nofiles.text":     "No ICS files were generated yet.",
+		"cal.class":            "Class %s",
+		"cal.general":          "General",
+		"cal.other":            "Unrecognized course names",
+		"cal.current":          "%s – current and next block",
+		"cal.files":            "%d files",
+		"res.calendars":        "%d calendars",
+		"res.none":             "No timetables were generated yet.",
+		"page.index":           "ASW Class Calendars",
+		"page.index.sub":       "Aggregated calendars per class/block. Recommended for subscription.",
+		"page.exams":           "ASW Exam Calendars",
+		"page.exams.sub":       "Only exams, presentations and submissions per class, with reminders ahead of each exam.",
+		"page.all":             "ASW All Calendars",
+		"page.all.sub":         "All generated calendars including individual block files.",
+		"page.rooms":           "ASW Room Timetables",
+		"page.rooms.sub":       "Occupancy of each room across all classes.",
+		"page.lecturers":       "ASW Lecturer Timetables",
+		"page.lecturers.sub":   "Teaching schedule of each lecturer across all classes.",
+		"page.occupancy":       "ASW Room Occupancy",
+		"page.occupancy.sub":   "Which rooms are taken when, and which are free for your study group.",
+		"page.stats":           "ASW Contact Hours",
+		"page.stats.sub":       "Scheduled hours per class, module, event type and week. Cancelled events are not counted.",
+		"page.conflicts":       "ASW Schedule Conflicts",
+		"page.conflicts.sub":   "Double bookings and overlaps found in the merged data. Usually a data error at the source or a parsing bug.",
+		"page.preview":         "ASW Calendar Preview",
+		"page.preview.sub":     "See what a calendar contains before subscribing.",
+		"page.help":            "Google Calendar setup",
+		"page.help.sub":        "How to add these ASW calendars on Android and Google Calendar.",
+		"page.timetable":       "Timetable %s",
+		"page.timetable.sub":   "Weekly timetable merged from all blocks of the class. Same events as the class calendar.",
+		"page.handout":         "Calendars of class %s",
+		"page.handout.sub":     "Scan a code with your phone camera to subscribe. The calendars update automatically.",
+		"help.opt1":            "Option 1: Subscribe by URL (recommended)",
+		"help.opt1.sub":        "Best for automatic updates.",
+		"help.opt2":            "Option 2: Import the file",
+		"help.opt2.sub":        "Good for one-time import, not ideal for updates.",
+		"help.steps":           "Steps",
+		"help.web.html":        "Open <a href=\"https://calendar.google.com\" target=\"_blank\" rel=\"noopener noreferrer\">Google Calendar</a> on the web",
+		"help.web.sub":         "Use a browser on Android or desktop.",
+		"help.url":             "Go to “Other calendars” → “From URL”",
+		"help.url.sub":         "This menu is not reliably available in the Android app.",
+		"help.copy":            "Copy the HTTPS link from this site",
+		"help.copy.sub":        "Use the “Copy URL” button next to your class calendar.",
+		"help.paste":           "Paste the link and confirm",
+		"help.paste.sub":       "The calendar should appear and update automatically.",
+		"help.download":        "Tap “Download file”",
+		"help.download.sub":    "Download the .ics file to your device.",
+		"help.open":            "Open it with your calendar app",
+		"help.open.sub":        "Depending on vendor apps, the import dialog appears automatically.",
+		"help.note":            "Note",
+		"help.note.text":       "The “Subscribe” button uses the webcal protocol which is best supported on Apple devices. On many Android setups, the safest path is using the HTTPS link or importing the file.",
+		"pv.loading":           "Loading…",
+		"pv.month":             "Month",
+		"pv.week":              "Week",
+		"pv.agenda":            "Agenda",
+		"pv.today":             "Today",
+		"tt.prev":              "‹ Previous",
+		"tt.next":              "Next ›",
+		"tt.print":             "Print week",
+		"tt.printAll":          "Print all weeks",
+		"ho.print":             "Print",
+		"ho.steps":             "iPhone: scan the webcal code and confirm the subscription. Android: scan the HTTPS code, copy the link and add it in Google Calendar on the web under “Other calendars” → “From URL”.",
+		"ho.class":             "Class calendar",
+		"ho.exams":             "Exams",
+		"ho.current":           "Current and next block",
+		"ho.modules":           "Single modules",
+		"page.changes":         "Changes %s",
+		"page.changes.sub":     "Schedule changes detected between runs, newest first.",
+		"chg.keep":             "Changes are kept for %d days.",
+		"chg.none":             "No changes detected",
+		"chg.none.text":        "The schedule of this calendar has not changed recently.",
+		"chg.detected":         "Detected %s",
+		"chg.added":            "New",
+		"chg.removed":          "Removed",
+		"chg.changed":          "Changed",
+		"chg.moved":            "Moved",
+		"chg.confirmed":        "takes place",
+		"chg.f.time":           "Time",
+		"chg.f.summary":        "Title",
+		"chg.f.location":       "Room",
+		"chg.f.instructor":     "Lecturer",
+		"chg.f.status":         "Status",
+		"tt.prevWeek":          "‹ Previous week",
+		"tt.nextWeek":          "Next week ›",
+		"tt.none":              "No events",
+		"tt.studyWeek":         "Study week %d · %s",
+		"occ.find":             "Find a free room",
+		"occ.show":             "Show free rooms",
+		"occ.note":             "Only rooms that appear in at least one schedule are known. A room counts as free when no parsed event uses it.",
+		"occ.none":             "No rooms",
+		"occ.none.text":        "No room bookings were found in the parsed schedules.",
+		"occ.week":             "Week of %s",
+		"occ.rooms":            "%d rooms",
+		"occ.room":             "Room",
+		"st.classes":           "Classes",
+		"st.csv":               "Download CSV",
+		"st.csv.text":          "One row per class, block, week, module and type.",
+		"st.none":              "No events were parsed.",
+		"st.class":             "Class",
+		"st.weekly":            "Weekly load",
+		"st.blocks":            "Modules per block",
+		"st.perSemester":       "Per semester",
+		"st.semester":          "Semester",
+		"st.perType":           "Per event type",
+		"st.type":              "Type",
+		"st.module":            "Module",
+		"st.events":            "Events",
+		"st.hours":             "Hours",
+		"st.total":             "Total",
+		"conf.none":            "No conflicts",
+		"conf.none.text":       "No double bookings or overlaps were detected.",
+		"conf.room":            "Room double bookings",
+		"conf.instructor":      "Lecturers in two places at once",
+		"conf.class":           "Overlapping events within a class",
+		"js.copied":            "Copied",
+		"js.copyPrompt":        "Copy this URL:",
+		"js.subscribe":         "Subscribe",
+		"js.copy":              "Copy URL",
+		"js.download":          "Download file",
+		"js.show":              "Show",
+		"js.preview":           "Preview",
+		"js.noMatch":           "No calendar matches.",
+		"js.kind.class":        "Class",
+		"js.kind.current":      "Current blocks",
+		"js.kind.module":       "Module",
+		"js.kind.course":       "Block",
+		"js.kind.exams":        "Exams",
+		"js.kind.room":         "Room",
+		"js.kind.lecturer":     "Lecturer",
+		"js.noCalendar":        "No calendar selected",
+		"js.notFound":          "Calendar not found: %s",
+		"js.eventCount":        "%d events",
+		"js.previewTitle":      "%s – Preview",
+		"js.noEvents":          "No events in these four weeks.",
+		"js.weekdays":          "Mon,Tue,Wed,Thu,Fri,Sat,Sun",
+		"js.weekCol":           "CW",
+		"js.isoWeek":           "CW %d",
+		"js.studyWeek":         "study week %d",
+		"js.noFreeRoom":        "No known room is free at that time.",
+		"js.freeUntil":         "free until %s",
+		"js.freeRest":          "free for the rest of the day",
+		"js.stale":             "The data may be out of date: the last update was on %s.",
+	},
+	"de": {
+		"lang.name": "Deutsch",
+
+		"ics.title":            "ASW Stundenplan %s",
+		"ics.exams":            "%s Prüfungen",
+		"ics.current":          "%s Aktueller und nächster Block",
+		"ics.room":             "Raum %s",
+		"ics.event":            "ASW-Termin",
+		"ics.cancelled":        "Entfällt: %s",
+		"ics.course":           "Kurs: %s",
+		"ics.week":             "Studienwoche: %d (%s - %s)",
+		"ics.type":             "Art: %s",
+		"ics.module":           "Modul/Gruppe: %s",
+		"ics.instructor":       "Dozent: %s",
+		"ics.location":         "Ort: %s",
+		"ics.status":           "Status: %s (%s)",
+		"ics.status.TENTATIVE": "vorläufig",
+		"ics.status.CANCELLED": "entfällt",
+		"ics.note":             "Hinweis: %s",
+		"ics.reminder.exam":    "Prüfung steht an: %s",
+		"ics.reminder.travel":  "Zeit für den Weg nach %s: %s",
+		"weekdays":             "Mo,Di,Mi,Do,Fr,Sa,So",
+		"weekdays.long":        "Montag,Dienstag,Mittwoch,Donnerstag,Freitag,Samstag,Sonntag",
+		"csv.header":           "Datum,Wochentag,Beginn,Ende,Dauer,Modul,Art,Raum,Dozent,Kurs,Klasse,Studienwoche,Status",
+		"nav.individual":       "Einzelne Kalender anzeigen",
+		"nav.index":            "Zurück zu den Klassenkalendern",
+		"nav.exams":            "Prüfungskalender",
+		"nav.all":              "Alle Kalender",
+		"nav.rooms":            "Räume",
+		"nav.occupancy":        "Freie Räume",
+		"nav.lecturers":        "Dozenten",
+		"nav.stats":            "Statistik",
+		"nav.conflicts":        "Konflikte",
+		"nav.source":           "Quellseite",
+		"footer":               "Automatisch aktualisiert per GitHub Actions.",
+		"search.placeholder":   "Modul, Dozent, Raum, Klasse oder Block suchen…",
+		"info.title":           "Schnellstart",
+		"info.body.html":       "Mit <b>Abonnieren</b> wird der Kalender per webcal abonniert (am besten auf Apple-Geräten). Für Google Kalender unter Android/Windows <a href='help-google.html'>dieser Anleitung folgen</a>. Alternativ mit <b>URL kopieren</b> den Feed manuell hinzufügen oder mit <b>Datei herunterladen</b> einmalig importieren.",
+		"info.reminders":       "Erinnerungen vor Vorlesungen, Präsenzterminen und Prüfungen einschließen. Ausgeschaltet lassen, wenn die Kalender-App eigene Erinnerungen setzt.",
+		"row.subscribe":        "Abonnieren",
+		"row.copy":             "URL kopieren",
+		"row.download":         "Datei herunterladen",
+		"row.preview":          "Vorschau",
+		"row.timetable":        "Stundenplan",
+		"row.csv":              "CSV",
+		"row.event":            "1 Termin",
+		"row.events":           "%d Termine",
+		"row.changed":          "geändert %s",
+		"row.stale":            "Quelle seit %s nicht erfolgreich abgerufen",
+		"row.staleNever":       "Quelle noch nie erfolgreich abgerufen",
+		"header.asof":          "Datenstand: %s",
+		"row.qr":               "QR-Code",
+		"row.handout":          "Aushang",
+		"row.current":          "Aktueller Block",
+		"row.next":             "Nächster Block",
+		"row.changes":          "Änderungen",
+		"qr.https":             "Android / Google Kalender (HTTPS)",
+		"qr.webcal":            "iPhone / Mac (webcal)",
+		"qr.alt":               "QR-Code für %s",
+		"row.modules":          "Module",
+		"cal.nofiles":          "Keine Dateien",
+		"cal.nofiles.text":     "Es wurden noch keine ICS-Dateien erzeugt.",
+		"cal.class":            "Klasse %s",
+		"cal.general":          "Allgemein",
+		"cal.other":            "Nicht erkannte Kursnamen",
+		"cal.current":          "%s – aktueller und nächster Block",
+		"cal.files":            "%d Dateien",
+		"res.calendars":        "%d Kalender",
+		"res.none":             "Es wurden noch keine Stundenpläne erzeugt.",
+		"page.index":           "ASW Klassenkalender",
+		"page.index.sub":       "Zusammengefasste Kalender pro Klasse/Block. Zum Abonnieren empfohlen.",
+		"page.exams":           "ASW Prüfungskalender",
+		"page.exams.sub":       "Nur Prüfungen, Präsentationen und Abgaben pro Klasse, mit Erinnerungen vor jeder Prüfung.",
+		"page.all":             "ASW Alle Kalender",
+		"page.all.sub":         "Alle erzeugten Kalender einschließlich der einzelnen Blockdateien.",
+		"page.rooms":           "ASW Raumbelegung",
+		"page.rooms.sub":       "Belegung jedes Raums über alle Klassen.",
+		"page.lecturers":       "ASW Dozentenpläne",
+		"page.lecturers.sub":   "Lehrveranstaltungen jedes Dozenten über alle Klassen.",
+		"page.occupancy":       "ASW Raumauslastung",
+		"page.occupancy.sub":   "Welche Räume wann belegt sind und welche für eure Lerngruppe frei sind.",
+		"page.stats":           "ASW Kontaktstunden",
+		"page.stats.sub":       "Geplante Stunden pro Klasse, Modul, Veranstaltungsart und Woche. Ausgefallene Termine zählen nicht.",
+		"page.conflicts":       "ASW Terminkonflikte",
+		"page.conflicts.sub":   "Doppelbelegungen und Überschneidungen in den zusammengeführten Daten. Meist ein Datenfehler der Quelle oder ein Parserfehler.",
+		"page.preview":         "ASW Kalendervorschau",
+		"page.preview.sub":     "Vor dem Abonnieren sehen, was ein Kalender enthält.",
+		"page.help":            "Einrichtung in Google Kalender",
+		"page.help.sub":        "So werden die ASW-Kalender unter Android und in Google Kalender hinzugefügt.",
+		"page.timetable":       "Stundenplan %s",
+		"page.timetable.sub":   "Wochenplan aus allen Blöcken der Klasse. Dieselben Termine wie im Klassenkalender.",
+		"page.handout.sub":     "Code mit der Handykamera scannen und den Kalender abonnieren. Die Kalender aktualisieren sich automatisch.",
+		"page.handout":         "Kalender der Klasse %s",
+		"help.opt1":            "Option 1: Per URL abonnieren (empfohlen)",
+		"help.opt1.sub":        "Am besten für automatische Aktualisierungen.",
+		"help.opt2":            "Option 2: Datei importieren",
+		"help.opt2.sub":        "Gut für einen einmaligen Import, nicht für Aktualisierungen.",
+		"help.steps":           "Schritte",
+		"help.web.html":        "<a href=\"https://calendar.google.com\" target=\"_blank\" rel=\"noopener noreferrer\">Google Kalender</a> im Web öffnen",
+		"help.web.sub":         "Einen Browser auf Android oder am Computer verwenden.",
+		"help.url":             "„Weitere Kalender“ → „Per URL“ wählen",
+		"help.url.sub":         "Dieses Menü ist in der Android-App nicht zuverlässig verfügbar.",
+		"help.copy":            "Den HTTPS-Link von dieser Seite kopieren",
+		"help.copy.sub":        "Die Schaltfläche „URL kopieren“ neben dem Klassenkalender verwenden.",
+		"help.paste":           "Link einfügen und bestätigen",
+		"help.paste.sub":       "Der Kalender erscheint und aktualisiert sich automatisch.",
+		"help.download":        "„Datei herunterladen“ antippen",
+		"help.download.sub":    "Die .ics-Datei auf das Gerät laden.",
+		"help.open":            "Mit der Kalender-App öffnen",
+		"help.open.sub":        "Je nach Hersteller-App erscheint der Import-Dialog automatisch.",
+		"help.note":            "Hinweis",
+		"help.note.text":       "„Abonnieren“ nutzt das webcal-Protokoll, das am besten auf Apple-Geräten funktioniert. Unter Android ist der HTTPS-Link oder der Dateiimport meist der sicherste Weg.",
+		"pv.loading":           "Lade…",
+		"pv.month":             "Monat",
+		"pv.week":              "Woche",
+		"pv.agenda":            "Liste",
+		"pv.today":             "Heute",
+		"tt.prev":              "‹ Zurück",
+		"tt.next":              "Weiter ›",
+		"tt.print":             "Woche drucken",
+		"tt.printAll":          "Alle Wochen drucken",
+		"ho.print":             "Drucken",
+		"ho.steps":             "iPhone: den webcal-Code scannen und das Abonnement bestätigen. Android: den HTTPS-Code scannen, den Link kopieren und im Google Kalender im Web unter „Weitere Kalender“ → „Per URL“ hinzufügen.",
+		"ho.class":             "Klassenkalender",
+		"ho.exams":             "Prüfungen",
+		"ho.current":           "Aktueller und nächster Block",
+		"ho.modules":           "Einzelne Module",
+		"page.changes":         "Änderungen %s",
+		"page.changes.sub":     "Zwischen den Läufen erkannte Stundenplanänderungen, neueste zuerst.",
+		"chg.keep":             "Änderungen werden %d Tage lang aufbewahrt.",
+		"chg.none":             "Keine Änderungen erkannt",
+		"chg.none.text":        "Der Stundenplan dieses Kalenders hat sich in letzter Zeit nicht geändert.",
+		"chg.detected":         "Erkannt am %s",
+		"chg.added":            "Neu",
+		"chg.removed":          "Entfernt",
+		"chg.changed":          "Geändert",
+		"chg.moved":            "Verschoben",
+		"chg.confirmed":        "findet statt",
+		"chg.f.time":           "Zeit",
+		"chg.f.summary":        "Titel",
+		"chg.f.location":       "Raum",
+		"chg.f.instructor":     "Dozent",
+		"chg.f.status":         "Status",
+		"tt.prevWeek":          "‹ Vorherige Woche",
+		"tt.nextWeek":          "Nächste Woche ›",
+		"tt.none":              "Keine Termine",
+		"tt.studyWeek":         "%d. Studienwoche · %s",
+		"occ.find":             "Freien Raum finden",
+		"occ.show":             "Freie Räume anzeigen",
+		"occ.note":             "Bekannt sind nur Räume, die in mindestens einem Plan vorkommen. Ein Raum gilt als frei, wenn kein erfasster Termin ihn belegt.",
+		"occ.none":             "Keine Räume",
+		"occ.none.text":        "In den Plänen wurden keine Raumbelegungen gefunden.",
+		"occ.week":             "Woche ab %s",
+		"occ.rooms":            "%d Räume",
+		"occ.room":             "Raum",
+		"st.classes":           "Klassen",
+		"st.csv":               "CSV herunterladen",
+		"st.csv.text":          "Eine Zeile pro Klasse, Block, Woche, Modul und Art.",
+		"st.none":              "Es wurden keine Termine erfasst.",
+		"st.class":             "Klasse",
+		"st.weekly":            "Wochenbelastung",
+		"st.blocks":            "Module pro Block",
+		"st.perSemester":       "Pro Semester",
+		"st.semester":          "Semester",
+		"st.perType":           "Pro Veranstaltungsart",
+		"st.type":              "Art",
+		"st.module":            "Modul",
+		"st.events":            "Termine",
+		"st.hours":             "Stunden",
+		"st.total":             "Summe",
+		"conf.none":            "Keine Konflikte",
+		"conf.none.text":       "Es wurden keine Doppelbelegungen oder Überschneidungen gefunden.",
+		"conf.room":            "Doppelt belegte Räume",
+		"conf.instructor":      "Dozenten an zwei Orten gleichzeitig",
+		"conf.class":           "Überschneidungen innerhalb einer Klasse",
+		"js.copied":            "Kopiert",
+		"js.copyPrompt":        "Diese URL kopieren:",
+		"js.subscribe":         "Abonnieren",
+		"js.copy":              "URL kopieren",
+		"js.download":          "Datei herunterladen",
+		"js.show":              "Anzeigen",
+		"js.preview":           "Vorschau",
+		"js.noMatch":           "Kein Kalender gefunden.",
+		"js.kind.class":        "Klasse",
+		"js.kind.current":      "Aktuelle Blöcke",
+		"js.kind.module":       "Modul",
+		"js.kind.course":       "Block",
+		"js.kind.exams":        "Prüfungen",
+		"js.kind.room":         "Raum",
+		"js.kind.lecturer":     "Dozent",
+		"js.noCalendar":        "Kein Kalender ausgewählt",
+		"js.notFound":          "Kalender nicht gefunden: %s",
+		"js.eventCount":        "%d Termine",
+		"js.previewTitle":      "%s – Vorschau",
+		"js.noEvents":          "Keine Termine in diesen vier Wochen.",
+		"js.weekdays":          "Mo,Di,Mi,Do,Fr,Sa,So",
+		"js.weekCol":           "KW",
+		"js.isoWeek":           "KW %d",
+		"js.studyWeek":         "%d. Studienwoche",
+		"js.noFreeRoom":        "Zu dieser Zeit ist kein bekannter Raum frei.",
+		"js.freeUntil":         "frei bis %s",
+		"js.freeRest":          "frei für den Rest des Tages",
+		"js.stale":             "Die Daten sind möglicherweise veraltet: die letzte Aktualisierung war am %s.",
+	},
+}
+
+// Look up a message, falling back to English and then to the key itself.
+func tr(lang, key string, args ...any) string {
+	msg, ok := messages[lang][key]
+	if !ok {
+		msg, ok = messages["en"][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Parse a comma-separated language list like "de,en".
+func parseLangs(s string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, l := range strings.Split(s, ",") {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" || seen[l] {
+			continue
+		}
+		if _, ok := messages[l]; !ok {
+			return nil, fmt.Errorf("unsupported language %q (use de or en)", l)
+		}
+		seen[l] = true
+		out = append(out, l)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no languages in %q", s)
+	}
+	return out, nil
+}
+
+// Text written into the calendars, in ASW_ICS_LANG.
+func icsText(key string, args ...any) string {
+	return tr(icsLang, key, args...)
+}
+
+// locale is the language of one rendered copy of the site. Pages and
+// partials use its methods: {{.T "key"}}, {{.HTML "key.html"}}, {{.Root}}.
+type locale struct {
+	Lang  string
+	Langs []string // all site languages, for the switcher
+}
+
+func siteLocales() ([]locale, error) {
+	langs, err := parseLangs(siteLangs)
+	if err != nil {
+		return nil, fmt.Errorf("ASW_SITE_LANGS: %w", err)
+	}
+	out := make([]locale, len(langs))
+	for i, l := range langs {
+		out[i] = locale{Lang: l, Langs: langs}
+	}
+	return out, nil
+}
+
+func (l locale) T(key string, args ...any) string {
+	return tr(l.Lang, key, args...)
+}
+
+// Message with markup; only for catalog keys ending in .html.
+func (l locale) HTML(key string) template.HTML {
+	return template.HTML(tr(l.Lang, key))
+}
+
+// Name of a language in that language, for the switcher.
+func (l locale) LangName(lang string) string {
+	return tr(lang, "lang.name")
+}
+
+// Relative path from the language folder to the public root, where the
+// calendars and data files are.
+func (l locale) Root() string {
+	return "../"
+}
+
+// js.* messages for the theme scripts, without the prefix.
+func (l locale) JSMessages() map[string]string {
+	out := map[string]string{}
+	for k := range messages["en"] {
+		if strings.HasPrefix(k, "js.") {
+			out[strings.TrimPrefix(k, "js.")] = tr(l.Lang, k)
+		}
+	}
+	return out
+}
+
+// Output path of a page in this language's folder.
+func (l locale) path(name string) string {
+	return filepath.Join(publicDir, l.Lang, name)
+}
+
+// Weekday abbreviation, "Mo" or "Mon".
+func (l locale) weekday(d time.Weekday) string {
+	return weekdayName(l.T("weekdays"), d)
+}
+
+// Full weekday name, "Montag" or "Monday".
+func (l locale) weekdayLong(d time.Weekday) string {
+	return weekdayName(l.T("weekdays.long"), d)
+}
+
+// Pick a day from a comma-separated list starting on Monday.
+func weekdayName(list string, d time.Weekday) string {
+	names := strings.Split(list, ",")
+	i := (int(d) + 6) % 7
+	if i >= len(names) {
+		return d.String()
+	}
+	return names[i]
+}
+
+// Data of a redirect page in the public root (pages/redirect.html).
+type redirectPage struct {
+	File    string   // page name, e.g. "index.html"
+	Default string   // default language, the target without JavaScript
+	Langs   []string // site languages, default first
+}
+
+// Write a page in the public root for every page of the default language
+// that forwards to the same page in the visitor's language, so links from
+// before the site was localized keep working.
+func writeLanguageRedirects(locs []locale) error {
+	pages, err := filepath.Glob(filepath.Join(publicDir, locs[0].Lang, "*.html"))
+	if err != nil {
+		return err
+	}
+	langs := make([]string, len(locs))
+	for i, l := range locs {
+		langs[i] = l.Lang
+	}
+
+	for _, p := range pages {
+		name := filepath.Base(p)
+		page := redirectPage{File: name, Default: locs[0].Lang, Langs: langs}
+		if err := renderThemeFile("redirect.html", filepath.Join(publicDir, name), page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var verbRe = regexp.MustCompile(`%[sd]`)
+
+// Every language must have every message, with the same placeholders in the
+// same order, or fmt.Sprintf output turns into %!d(string=…).
+func TestMessageCatalogs(t *testing.T) {
+	for lang, msgs := range messages {
+		for key, en := range messages["en"] {
+			m, ok := msgs[key]
+			if !ok {
+				t.Errorf("%s: missing %q", lang, key)
+				continue
+			}
+			if a, b := verbRe.FindAllString(en, -1), verbRe.FindAllString(m, -1); strings.Join(a, "") != strings.Join(b, "") {
+				t.Errorf("%s: %q has placeholders %v, en has %v", lang, key, b, a)
+			}
+		}
+		for key := range msgs {
+			if _, ok := messages["en"][key]; !ok {
+				t.Errorf("%s: %q is not in the en catalog", lang, key)
+			}
+		}
+	}
+}
+
+func TestParseLangs(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"de,en", []string{"de", "en"}, false},
+		{" EN , de, en ", []string{"en", "de"}, false},
+		{"en", []string{"en"}, false},
+		{"", nil, true},
+		{"de,fr", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseLangs(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLangs(%q) error = %v", tt.in, err)
+			continue
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("parseLangs(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	if got := tr("de", "cal.files", 3); got != "3 Dateien" {
+		t.Errorf("de cal.files = %q", got)
+	}
+	if got := tr("de", "no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key = %q", got)
+	}
+	if got := (locale{Lang: "de"}).JSMessages()["copied"]; got != "Kopiert" {
+		t.Errorf("JSMessages copied = %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"html"
 	"log"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -78,6 +80,7 @@ func getenv(key, def string) string {
 type ScheduleLink struct {
 	CourseName string
 	URL        string
+	Meta       courseMeta // parsed from CourseName
 }
 
 type ScheduleEvent struct {
@@ -87,9 +90,119 @@ type ScheduleEvent struct {
 	Description string
 	Start       time.Time
 	End         time.Time
+
+	// Status is derived from cell styling and marker text (see detectEventStatus).
+	// StatusNote keeps the marker that triggered it, e.g. "entfällt".
+	Status     EventStatus
+	StatusNote string
+
+	// Week metadata from the "n. Studienwoche" header above each week table.
+	Week      int
+	WeekLabel string
+	WeekStart time.Time
+	WeekEnd   time.Time
+
+	// Structured fields, classified from the cell lines by content (see classifyCellLines).
+	EventType  string // Vorlesung, Übung, Klausur, ...
+	Module     string
+	Group      string
+	Room       string // room without site prefix, e.g. "2.05"
+	Site       string // building/site prefix, e.g. "NK" or "EXT"
+	Instructor string
+	IsOnline   bool
+
+	// Provenance for debugging: the detail page, the sked cell id ("zf160234"),
+	// all cell lines as split and the lines no heuristic claimed.
+	SourceURL string
+	CellID    string
+	RawLines  []string
+	Extra     []string
+
+	// Footnotes: FootnoteRefs are the "[n]" markers found in the cell,
+	// Notes the texts they resolve to in the page's footnote legend.
+	FootnoteRefs []string
+	Notes        []string
+}
+
+// scheduleData is everything parsed in one run. The calendar writers and the
+// site generator both work from it, so the site never has to re-derive
+// content from filenames.
+type scheduleData struct {
+	Courses map[string][]ScheduleEvent // by course name, as parsed
+	Classes map[string][]ScheduleEvent // by class key, deduplicated
+	Failed  map[string]string          // course name -> fetch/parse error
+	Meta    map[string]courseMeta      // by course name, from the link text
+	Current map[string][]string        // class key -> current and next course, see currentBlocks
+	Links   int                        // schedule links found on the overview page
+}
+
+func newScheduleData() scheduleData {
+	return scheduleData{
+		Courses: map[string][]ScheduleEvent{},
+		Classes: map[string][]ScheduleEvent{},
+		Failed:  map[string]string{},
+		Meta:    map[string]courseMeta{},
+		Current: map[string][]string{},
+	}
+}
+
+// EventStatus mirrors the iCalendar STATUS values we emit per event.
+type EventStatus string
+
+const (
+	StatusConfirmed EventStatus = "CONFIRMED"
+	StatusTentative EventStatus = "TENTATIVE"
+	StatusCancelled EventStatus = "CANCELLED"
+)
+
+var (
+	// sked campus marks changed slots with extra CSS classes next to "v".
+	// The exact class names depend on the sked configuration, so we match
+	// on substrings of the class attribute.
+	cancelledClassHints = []string{"ausfall", "entfall", "storno", "abgesagt", "cancel"}
+	changedClassHints   = []string{"aenderung", "geaendert", "verschoben", "verlegt", "change"}
+
+	// Marker text inside the cell. Checked case-insensitively against each line.
+	cancelledTextRe = regexp.MustCompile(`(?i)\b(entf(ä|ae)llt|f(ä|ae)llt\s+aus|abgesagt|ausfall|storniert)\b`)
+	changedTextRe   = regexp.MustCompile(`(?i)\b(verschoben|verlegt|ge(ä|ae)ndert|(raum|zeit)(ä|ae)nderung|vorl(ä|ae)ufig|unter\s+vorbehalt)\b`)
+)
+
+// weekInfo is the parsed form of a sked week header like
+// "7. Studienwoche: 08. - 14.12.2025".
+type weekInfo struct {
+	Number int
+	Label  string
+	Start  time.Time
+	End    time.Time
 }
 
+const usage = `usage:
+  asw-parser               fetch the schedule and generate all calendars and the site
+  asw-parser stats [flags] print contact hours (-h for flags)
+  asw-parser csv [flags]   print events as CSV (-h for flags)
+`
+
 func main() {
+	// Subcommands work on freshly parsed data and write nothing to the output dirs.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "stats":
+			if err := runStatsCommand(os.Args[2:]); err != nil {
+				log.Fatalf("stats: %v", err)
+			}
+			return
+		case "csv":
+			if err := runCSVCommand(os.Args[2:]); err != nil {
+				log.Fatalf("csv: %v", err)
+			}
+			return
+		default:
+			// Never fall through to a full run, which wipes the output dir.
+			fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+			os.Exit(2)
+		}
+	}
+
 	log.Println("ASW schedule parser and ICS generator started")
 
 	// Clean output dir for deterministic results
@@ -100,32 +213,93 @@ func main() {
 		log.Fatalf("failed to create output dir: %v", err)
 	}
 
+	data, err := loadSchedule()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// 1) Generate individual block ICS.
+	for _, courseName := range eventKeys(data.Courses) {
+		events := data.Courses[courseName]
+		if err := generateICS(courseName, events); err != nil {
+			log.Printf("failed to generate ICS for %s: %v", courseName, err)
+		} else {
+			log.Printf("ICS created for %s with %d events", courseName, len(events))
+		}
+	}
+
+	// 2) Generate aggregated ICS per class (plus the derived exam and module calendars).
+	for _, classKey := range eventKeys(data.Classes) {
+		evs := data.Classes[classKey]
+		if err := generateClassCalendars(classKey, evs); err != nil {
+			log.Printf("failed to generate aggregated ICS for %s: %v", classKey, err)
+			continue
+		}
+		log.Printf("aggregated ICS created for %s with %d events", classKey, len(evs))
+
+		if cur := data.currentEvents(classKey); len(cur) > 0 {
+			if err := generateCurrentICS(classKey, cur); err != nil {
+				log.Printf("failed to generate current block ICS for %s: %v", classKey, err)
+			} else {
+				log.Printf("current block ICS created for %s (%s)", classKey, strings.Join(data.Current[classKey], ", "))
+			}
+		}
+	}
+
+	// 3) Timetables per room and lecturer across all classes.
+	if err := generateResourceCalendars(data); err != nil {
+		log.Printf("failed to generate room/lecturer ICS: %v", err)
+	}
+
+	log.Printf("done. files are in: %s", outputDir)
+
+	if err := generateSite(data); err != nil {
+		log.Printf("warning: site generation failed: %v", err)
+	}
+}
+
+// Fetch the overview page and every schedule it links to, and aggregate the
+// events per class. Nothing is written; single-course failures are recorded
+// in data.Failed instead of aborting the run.
+func loadSchedule() (scheduleData, error) {
+	data := newScheduleData()
+
+	if _, err := parseLangs(icsLang); err != nil {
+		return data, fmt.Errorf("ASW_ICS_LANG: %w", err)
+	}
+
 	isLocalMode, localBaseDir := detectLocalMode(scheduleURL)
 
 	links, err := parseMainSchedulePage(scheduleURL, isLocalMode, localBaseDir)
 	if err != nil {
-		log.Fatalf("failed to parse main schedule page: %v", err)
+		return data, fmt.Errorf("failed to parse main schedule page: %w", err)
 	}
 
 	// Guard only for HTTP mode
 	if !isLocalMode && len(links) < minExpectedLinks {
-		log.Fatalf(
+		return data, fmt.Errorf(
 			"critical: only %d links found (expected > %d). Page structure may have changed.",
 			len(links), minExpectedLinks,
 		)
 	}
 
 	log.Printf("found %d schedule links, starting generation", len(links))
+	data.Links = len(links)
 
 	// Collect aggregated events per class key.
 	classEvents := map[string][]ScheduleEvent{}
 
 	for _, link := range links {
 		log.Printf("processing course: %s", link.CourseName)
+		data.Meta[link.CourseName] = link.Meta
+		if !link.Meta.Known() {
+			log.Printf("warning: unrecognized course name %q, listed under %s", link.CourseName, otherClass)
+		}
 
 		events, err := parseScheduleDetails(link)
 		if err != nil {
 			log.Printf("warning: failed to parse %s: %v", link.CourseName, err)
+			data.Failed[link.CourseName] = err.Error()
 			continue
 		}
 
@@ -134,39 +308,33 @@ func main() {
 			continue
 		}
 
-		// 1) Generate individual block ICS.
-		if err := generateICS(link.CourseName, events); err != nil {
-			log.Printf("failed to generate ICS for %s: %v", link.CourseName, err)
-		} else {
-			log.Printf("ICS created for %s with %d events", link.CourseName, len(events))
-		}
-
-		// 2) Add to aggregated class bucket.
-		classKey := extractClassKey(link.CourseName)
+		data.Courses[link.CourseName] = events
+		classKey := link.Meta.Key()
 		classEvents[classKey] = append(classEvents[classKey], events...)
 	}
 
-	// 3) Generate aggregated ICS per class.
 	for classKey, evs := range classEvents {
 		if len(evs) == 0 {
 			continue
 		}
-
 		// Optional hardening: deduplicate aggregated events.
-		evs = dedupeEvents(evs)
-
-		if err := generateICS(classKey, evs); err != nil {
-			log.Printf("failed to generate aggregated ICS for %s: %v", classKey, err)
-			continue
+		data.Classes[classKey] = dedupeEvents(evs)
+		if cur := data.currentBlocks(classKey, time.Now()); len(cur) > 0 {
+			data.Current[classKey] = cur
 		}
-		log.Printf("aggregated ICS created for %s with %d events", classKey, len(evs))
 	}
 
-	log.Printf("done. files are in: %s", outputDir)
+	return data, nil
+}
 
-	if err := generateSite(); err != nil {
-		log.Printf("warning: site generation failed: %v", err)
+// Keys of an event map in sorted order, for deterministic output.
+func eventKeys(m map[string][]ScheduleEvent) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+	return keys
 }
 
 func detectLocalMode(url string) (bool, string) {
@@ -275,9 +443,11 @@ func parseMainSchedulePage(url string, isLocalMode bool, localBaseDir string) ([
 		fullURL := resolveURL(href, isLocalMode, localBaseDir)
 
 		if reText.MatchString(text) || reHref.MatchString(href) {
+			name := normalizeCourseName(text)
 			extracted = append(extracted, ScheduleLink{
-				CourseName: normalizeCourseName(text),
+				CourseName: name,
 				URL:        fullURL,
+				Meta:       parseCourseName(name),
 			})
 		}
 	})
@@ -310,22 +480,38 @@ func parseScheduleDetails(link ScheduleLink) ([]ScheduleEvent, error) {
 	}
 
 	var all []ScheduleEvent
+	var week weekInfo
+
+	// Each week is represented by a table preceded by a div.w2 week header.
+	// We walk both in document order so every table knows its week,
+	// then extract td.v cells with grid mapping.
+	doc.Find("div.w2, table").Each(func(_ int, s *goquery.Selection) {
+		if goquery.NodeName(s) == "div" {
+			week = parseWeekHeader(s.Text())
+			return
+		}
 
-	// Each week is represented by a table. We parse all tables and extract td.v cells with grid mapping.
-	doc.Find("table").Each(func(_ int, table *goquery.Selection) {
-		evs := parseWeekTable(table, link.CourseName, loc)
+		evs := parseWeekTable(s, link.CourseName, week, loc)
+		for i := range evs {
+			evs[i].SourceURL = link.URL
+		}
 		if len(evs) > 0 {
 			all = append(all, evs...)
 		}
 	})
 
+	legend := extractFootnoteLegend(doc)
+	for i := range all {
+		resolveFootnotes(&all[i], legend)
+	}
+
 	return all, nil
 }
 
 // Parse one weekly schedule table.
 // We map header dates to logical columns and then place body cells into a grid
 // using colspan/rowspan to determine the date for each td.v event cell.
-func parseWeekTable(table *goquery.Selection, courseName string, loc *time.Location) []ScheduleEvent {
+func parseWeekTable(table *goquery.Selection, courseName string, week weekInfo, loc *time.Location) []ScheduleEvent {
 	var events []ScheduleEvent
 
 	rows := table.Find("tr")
@@ -339,6 +525,8 @@ func parseWeekTable(table *goquery.Selection, courseName string, loc *time.Locat
 		return events
 	}
 
+	checkWeekRange(week, dateByCol, courseName)
+
 	// Occupancy array for rowspans across logical columns.
 	occ := make([]int, totalCols)
 
@@ -381,7 +569,7 @@ func parseWeekTable(table *goquery.Selection, courseName string, loc *time.Locat
 			if hasClass(cell, "v") {
 				date, ok := dateByCol[startCol]
 				if ok && !date.IsZero() {
-					if ev, ok := parseEventCell(cell, date, courseName, loc); ok {
+					if ev, ok := parseEventCell(cell, date, courseName, week, loc); ok {
 						events = append(events, ev)
 					}
 				}
@@ -435,8 +623,74 @@ func extractHeaderDates(headerRow *goquery.Selection) (map[int]time.Time, int) {
 	return dateByCol, total
 }
 
+// Parse a week header like "7. Studienwoche: 08. - 14.12.2025".
+// The start date may omit month and year ("08. - 14.12.2025") or just the year
+// ("29.12. - 04.01.2026"); missing parts are taken from the end date.
+// Returns a zero weekInfo if the text does not look like a week header.
+func parseWeekHeader(text string) weekInfo {
+	text = strings.Join(strings.Fields(text), " ")
+
+	weekRe := regexp.MustCompile(`(\d{1,2})\.\s*Studienwoche\s*:?\s*(\d{1,2})\.(?:\s*(\d{1,2})\.)?(?:\s*(\d{4}))?\s*[-–—]\s*(\d{1,2}\.\s*\d{1,2}\.\s*\d{4})`)
+	m := weekRe.FindStringSubmatch(text)
+	if len(m) != 6 {
+		return weekInfo{}
+	}
+
+	num, err := strconv.Atoi(m[1])
+	if err != nil {
+		return weekInfo{}
+	}
+
+	end, err := time.Parse(dateFormat, strings.ReplaceAll(m[5], " ", ""))
+	if err != nil {
+		return weekInfo{}
+	}
+
+	day, _ := strconv.Atoi(m[2])
+	month := int(end.Month())
+	if m[3] != "" {
+		month, _ = strconv.Atoi(m[3])
+	}
+	year := end.Year()
+	if m[4] != "" {
+		year, _ = strconv.Atoi(m[4])
+	} else if month > int(end.Month()) {
+		// Week spans the turn of the year: "29.12. - 04.01.2026".
+		year--
+	}
+
+	start := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if start.After(end) || start.Day() != day {
+		return weekInfo{}
+	}
+
+	return weekInfo{
+		Number: num,
+		Label:  strings.TrimSpace(m[0]),
+		Start:  start,
+		End:    end,
+	}
+}
+
+// Cross-check the week header against the dates found in the table header.
+// A mismatch usually means the page layout shifted and the header belongs to
+// a different table, so we only warn and keep parsing.
+func checkWeekRange(week weekInfo, dateByCol map[int]time.Time, courseName string) {
+	if week.Number == 0 {
+		return
+	}
+
+	for _, d := range dateByCol {
+		if d.Before(week.Start) || d.After(week.End) {
+			log.Printf("warning: %s: week header %q does not match table date %s",
+				courseName, week.Label, d.Format(dateFormat))
+			return
+		}
+	}
+}
+
 // Parse a td.v cell into a ScheduleEvent.
-func parseEventCell(cell *goquery.Selection, date time.Time, courseName string, loc *time.Location) (ScheduleEvent, bool) {
+func parseEventCell(cell *goquery.Selection, date time.Time, courseName string, week weekInfo, loc *time.Location) (ScheduleEvent, bool) {
 	rawHTML, err := cell.Html()
 	if err != nil {
 		return ScheduleEvent{}, false
@@ -457,23 +711,10 @@ func parseEventCell(cell *goquery.Selection, date time.Time, courseName string,
 		return ScheduleEvent{}, false
 	}
 
-	typeLine := ""
-	moduleLine := ""
-	locationLine := ""
-	extra := []string{}
-
-	if len(lines) > 1 {
-		typeLine = lines[1]
-	}
-	if len(lines) > 2 {
-		moduleLine = lines[2]
-	}
-	if len(lines) > 3 {
-		locationLine = lines[3]
-	}
-	if len(lines) > 4 {
-		extra = lines[4:]
-	}
+	// Status must be read before the marker lines are dropped.
+	rawLines := lines
+	status, statusNote := detectEventStatus(cell, lines)
+	lines = dropStatusLines(lines)
 
 	startHour, startMin, ok := parseClock(startStr)
 	if !ok {
@@ -487,60 +728,265 @@ func parseEventCell(cell *goquery.Selection, date time.Time, courseName string,
 	start := time.Date(date.Year(), date.Month(), date.Day(), startHour, startMin, 0, 0, loc)
 	end := time.Date(date.Year(), date.Month(), date.Day(), endHour, endMin, 0, 0, loc)
 
-	// Build summary with pragmatic rules.
-	summary := moduleLine
+	f := classifyCellLines(lines)
+
+	ev := ScheduleEvent{
+		CourseName: courseName,
+		Location:   f.Location,
+		Start:      start,
+		End:        end,
+		Status:     status,
+		StatusNote: statusNote,
+		Week:       week.Number,
+		WeekLabel:  week.Label,
+		WeekStart:  week.Start,
+		WeekEnd:    week.End,
+		EventType:  f.EventType,
+		Module:     f.Module,
+		Group:      f.Group,
+		Room:       f.Room,
+		Site:       f.Site,
+		Instructor: f.Instructor,
+		IsOnline:   f.IsOnline,
+		CellID:     cell.AttrOr("id", ""),
+		RawLines:   rawLines,
+		Extra:      f.Extra,
+
+		FootnoteRefs: footnoteRefs(cell.Text()),
+	}
+	ev.Summary = summarizeEvent(ev)
+	ev.Description = describeEvent(ev)
+
+	return ev, true
+}
+
+// Build the event title with pragmatic rules: "Module (Type)" where possible.
+func summarizeEvent(e ScheduleEvent) string {
+	summary := e.Module
 	if summary == "" {
-		summary = typeLine
+		summary = e.EventType
 	}
 	if summary == "" {
-		summary = "ASW event"
+		summary = icsText("ics.event")
 	}
-	if typeLine != "" && moduleLine != "" && !strings.Contains(strings.ToLower(moduleLine), strings.ToLower(typeLine)) {
-		summary = fmt.Sprintf("%s (%s)", moduleLine, typeLine)
+	if e.EventType != "" && e.Module != "" && !strings.Contains(strings.ToLower(e.Module), strings.ToLower(e.EventType)) {
+		summary = fmt.Sprintf("%s (%s)", e.Module, e.EventType)
 	}
-
-	// Try to determine location.
-	location := locationLine
-	if location == "" {
-		for _, l := range append([]string{typeLine, moduleLine}, extra...) {
-			if strings.HasPrefix(l, "NK:") || strings.HasPrefix(l, "EXT:") {
-				location = l
-				break
-			}
-		}
+	if e.Status == StatusCancelled {
+		// Not every client renders STATUS:CANCELLED, so make it visible in the title too.
+		summary = icsText("ics.cancelled", summary)
 	}
+	return summary
+}
 
+// Build the event description from the structured fields.
+func describeEvent(e ScheduleEvent) string {
 	descParts := []string{
-		fmt.Sprintf("Course: %s", courseName),
+		icsText("ics.course", e.CourseName),
+	}
+	if e.Week > 0 {
+		descParts = append(descParts, icsText("ics.week",
+			e.Week, e.WeekStart.Format(dateFormat), e.WeekEnd.Format(dateFormat)))
+	}
+	if e.EventType != "" {
+		descParts = append(descParts, icsText("ics.type", e.EventType))
+	}
+	if e.Module != "" || e.Group != "" {
+		mg := e.Module
+		if e.Group != "" && mg != "" {
+			mg += " / "
+		}
+		mg += e.Group
+		descParts = append(descParts, icsText("ics.module", mg))
 	}
-	if typeLine != "" {
-		descParts = append(descParts, fmt.Sprintf("Type: %s", typeLine))
+	if e.Instructor != "" {
+		descParts = append(descParts, icsText("ics.instructor", e.Instructor))
 	}
-	if moduleLine != "" {
-		descParts = append(descParts, fmt.Sprintf("Module/Group: %s", moduleLine))
+	if e.Location != "" {
+		descParts = append(descParts, icsText("ics.location", e.Location))
 	}
-	if location != "" {
-		descParts = append(descParts, fmt.Sprintf("Location: %s", location))
+	if e.Status != StatusConfirmed && e.Status != "" {
+		descParts = append(descParts, icsText("ics.status", icsText("ics.status."+string(e.Status)), e.StatusNote))
 	}
-	for _, l := range extra {
+	for _, l := range e.Extra {
 		if l != "" {
 			descParts = append(descParts, l)
 		}
 	}
+	for _, n := range e.Notes {
+		descParts = append(descParts, icsText("ics.note", n))
+	}
 
-	description := strings.Join(descParts, "\n")
+	return strings.Join(descParts, "\n")
+}
 
-	return ScheduleEvent{
-		CourseName:  courseName,
-		Summary:     summary,
-		Location:    location,
-		Description: description,
-		Start:       start,
-		End:         end,
-	}, true
+// Determine whether a td.v cell is cancelled or changed.
+// Checked in order of reliability: extra CSS classes on the cell, strike-through
+// markup inside it, then marker text. Cancellation wins over changes.
+// The returned note names what triggered the status, for the description.
+func detectEventStatus(cell *goquery.Selection, lines []string) (EventStatus, string) {
+	classes := strings.ToLower(cell.AttrOr("class", ""))
+	for _, hint := range cancelledClassHints {
+		if strings.Contains(classes, hint) {
+			return StatusCancelled, "class " + hint
+		}
+	}
+
+	if cell.Find("s, strike, del").Length() > 0 || hasLineThrough(cell) {
+		return StatusCancelled, "struck through"
+	}
+
+	for _, l := range lines {
+		if m := cancelledTextRe.FindString(l); m != "" {
+			return StatusCancelled, m
+		}
+	}
+
+	for _, hint := range changedClassHints {
+		if strings.Contains(classes, hint) {
+			return StatusTentative, "class " + hint
+		}
+	}
+
+	for _, l := range lines {
+		if m := changedTextRe.FindString(l); m != "" {
+			return StatusTentative, m
+		}
+	}
+
+	return StatusConfirmed, ""
+}
+
+// Report whether the cell or any element inside it is styled as line-through.
+func hasLineThrough(cell *goquery.Selection) bool {
+	styled := cell.Find("[style]").AddSelection(cell)
+	found := false
+	styled.EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		style := strings.ToLower(s.AttrOr("style", ""))
+		found = strings.Contains(style, "line-through")
+		return !found
+	})
+	return found
+}
+
+// Drop lines that consist only of a status marker ("entfällt!", "verschoben"),
+// so they are not mistaken for the type, module or location line.
+// The time line (index 0) is always kept.
+func dropStatusLines(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for i, l := range lines {
+		if i > 0 {
+			bare := strings.Trim(l, " !.:-()*")
+			if cancelledTextRe.FindString(bare) == bare || changedTextRe.FindString(bare) == bare {
+				continue
+			}
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+var (
+	footnoteRefRe    = regexp.MustCompile(`\[(\d+)\]`)
+	footnoteLegendRe = regexp.MustCompile(`^\[(\d+)\]\s*[:.-]?\s*(.+)$`)
+)
+
+// Collect the footnote numbers referenced in a cell, in order and without duplicates.
+func footnoteRefs(text string) []string {
+	var refs []string
+	seen := map[string]bool{}
+	for _, m := range footnoteRefRe.FindAllStringSubmatch(text, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			refs = append(refs, m[1])
+		}
+	}
+	return refs
+}
+
+// Extract the footnote legend sked prints below the week tables, e.g.
+// "[1] findet online statt". The event cells carry the same markers,
+// so we drop them first and then read every remaining line of the page
+// that starts with a marker.
+func extractFootnoteLegend(doc *goquery.Document) map[string]string {
+	legend := map[string]string{}
+
+	page := doc.Find("body").Clone()
+	page.Find("td.v").Remove()
+
+	rawHTML, err := page.Html()
+	if err != nil {
+		return legend
+	}
+
+	// Block boundaries become line breaks so splitting by line works like in cells.
+	blockEndRe := regexp.MustCompile(`(?i)</(div|p|tr|td|th|li|table|h\d)>`)
+	rawHTML = blockEndRe.ReplaceAllString(rawHTML, "<br>")
+
+	for _, l := range splitLines(rawHTML) {
+		if m := footnoteLegendRe.FindStringSubmatch(l); len(m) == 3 {
+			if _, dup := legend[m[1]]; !dup {
+				legend[m[1]] = strings.TrimSpace(m[2])
+			}
+		}
+	}
+
+	return legend
+}
+
+// Attach the legend texts for an event's markers. Footnotes frequently carry
+// the actual news ("findet online statt", "entfällt"), so they also feed the
+// online flag and the status. Unknown markers are kept as "[n]" rather than lost.
+func resolveFootnotes(e *ScheduleEvent, legend map[string]string) {
+	if len(e.FootnoteRefs) == 0 {
+		return
+	}
+
+	e.Notes = nil
+	for _, ref := range e.FootnoteRefs {
+		text, ok := legend[ref]
+		if !ok {
+			text = "[" + ref + "]"
+		}
+		e.Notes = append(e.Notes, text)
+	}
+
+	if anyMatch(onlineRe, e.Notes) {
+		e.IsOnline = true
+	}
+	if e.Status == StatusConfirmed {
+		for _, n := range e.Notes {
+			if m := cancelledTextRe.FindString(n); m != "" {
+				e.Status, e.StatusNote = StatusCancelled, m
+				break
+			}
+			if m := changedTextRe.FindString(n); m != "" {
+				e.Status, e.StatusNote = StatusTentative, m
+			}
+		}
+	}
+
+	e.Summary = summarizeEvent(*e)
+	e.Description = describeEvent(*e)
 }
 
 func splitCellLines(rawHTML string) []string {
+	lines := splitLines(rawHTML)
+
+	// Footnote markers are collected separately (see footnoteRefs).
+	footnoteRe := regexp.MustCompile(`\s*\[\d+\]\s*`)
+	out := lines[:0]
+	for _, l := range lines {
+		l = strings.TrimSpace(footnoteRe.ReplaceAllString(l, " "))
+		if l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// Turn an HTML fragment into trimmed, non-empty text lines split at <br>.
+func splitLines(rawHTML string) []string {
 	// Normalize <br> variants to newline.
 	s := rawHTML
 	s = strings.ReplaceAll(s, "<br/>", "\n")
@@ -557,11 +1003,9 @@ func splitCellLines(rawHTML string) []string {
 	// Split and clean.
 	rawLines := strings.Split(s, "\n")
 	var lines []string
-	footnoteRe := regexp.MustCompile(`\s*\[\d+\]\s*`)
 
 	for _, l := range rawLines {
 		l = strings.TrimSpace(l)
-		l = footnoteRe.ReplaceAllString(l, "")
 		if l != "" {
 			lines = append(lines, l)
 		}
@@ -629,33 +1073,7 @@ func normalizeCourseName(s string) string {
 
 // Derive an aggregated class key from a course/block name.
 func extractClassKey(courseName string) string {
-	s := normalizeCourseName(courseName)
-
-	// Make the string easier to match.
-	s = strings.ReplaceAll(s, "_", "-")
-	s = strings.ReplaceAll(s, " ", "-")
-	s = strings.ReplaceAll(s, ".", "-")
-	s = regexp.MustCompile(`-{2,}`).ReplaceAllString(s, "-")
-
-	// 1) Letter-based class pattern: DBWINFO-A04, DBBWL-B03, etc.
-	reLetter := regexp.MustCompile(`\b(DB[A-Z]+)-([A-Z]\d{2,3})\b`)
-	if m := reLetter.FindStringSubmatch(s); len(m) == 3 {
-		return fmt.Sprintf("%s-%s", m[1], m[2])
-	}
-
-	// 2) Numeric cohort pattern: DBING-01, DBMAB-04, DBWI-05, etc.
-	reNum := regexp.MustCompile(`\b(DB[A-Z]+)-(\d{2})\b`)
-	if m := reNum.FindStringSubmatch(s); len(m) == 3 {
-		return fmt.Sprintf("%s-%s", m[1], m[2])
-	}
-
-	// 3) Fallback to program block
-	reBlock := regexp.MustCompile(`\b(DB[A-Z]+)\b`)
-	if m := reBlock.FindStringSubmatch(s); len(m) == 2 {
-		return m[1]
-	}
-
-	return "Other"
+	return parseCourseName(courseName).Key()
 }
 
 // Optional hardening for aggregated files.
@@ -664,12 +1082,13 @@ func dedupeEvents(in []ScheduleEvent) []ScheduleEvent {
 	out := make([]ScheduleEvent, 0, len(in))
 
 	for _, e := range in {
-		key := fmt.Sprintf("%d|%d|%s|%s|%s",
+		key := fmt.Sprintf("%d|%d|%s|%s|%s|%s",
 			e.Start.Unix(),
 			e.End.Unix(),
 			e.Summary,
 			e.Location,
 			e.Description,
+			e.Status,
 		)
 		if seen[key] {
 			continue
@@ -683,16 +1102,99 @@ func dedupeEvents(in []ScheduleEvent) []ScheduleEvent {
 
 // Step 3: Generate ICS file for one course or aggregated class.
 func generateICS(courseName string, events []ScheduleEvent) error {
+	return writeFeed(courseName, sanitizeName(courseName), extractClassKey(courseName), events)
+}
+
+// Write one feed: the plain calendar and, unless disabled, a variant with
+// VALARM reminders in the reminders/ subfolder. Some clients apply their own
+// default alarms, so users get to choose. classKey selects the reminder rules.
+func writeFeed(name, file, classKey string, events []ScheduleEvent) error {
+	if err := writeICS(name, name, file, events, nil); err != nil {
+		return err
+	}
+	if !reminderVariants {
+		return nil
+	}
+	return writeICS(name, name, "reminders/"+file, events, reminders.forClass(classKey))
+}
+
+// Write the aggregated calendar for one class plus the calendars derived from it
+// (exams, per-module).
+func generateClassCalendars(classKey string, events []ScheduleEvent) error {
+	if err := generateICS(classKey, events); err != nil {
+		return err
+	}
+
+	if exams := filterExams(events); len(exams) > 0 {
+		if err := generateExamICS(classKey, exams); err != nil {
+			return fmt.Errorf("exam calendar: %w", err)
+		}
+		log.Printf("exam ICS created for %s with %d events", classKey, len(exams))
+	}
+
+	if err := generateModuleCalendars(classKey, events); err != nil {
+		return fmt.Errorf("module calendars: %w", err)
+	}
+
+	return nil
+}
+
+// Write the calendar with the current and next block of a class, which
+// moves on by itself as the blocks go by.
+func generateCurrentICS(classKey string, events []ScheduleEvent) error {
+	title, file := icsText("ics.current", classKey), currentFile(classKey)
+	prefix := sanitizeName(classKey + " Current")
+	if err := writeICSUIDs(title, file, events, nil, eventUIDs(prefix)); err != nil {
+		return err
+	}
+	if !reminderVariants {
+		return nil
+	}
+	return writeICSUIDs(title, "reminders/"+file, events, reminders.forClass(classKey), eventUIDs(prefix))
+}
+
+// UIDs from the event IDs of the JSON export. The current-block calendar
+// switches blocks over time, so a position-based UID would move to another
+// event whenever the previous block drops out. Repeated IDs get a -2, -3
+// suffix like in the export.
+func eventUIDs(prefix string) func(int, ScheduleEvent) string {
+	seen := map[string]int{}
+	return func(_ int, e ScheduleEvent) string {
+		id := eventID(e)
+		seen[id]++
+		if n := seen[id]; n > 1 {
+			id += "-" + strconv.Itoa(n)
+		}
+		return prefix + "-" + id
+	}
+}
+
+// Sanitize a calendar name for use in filenames and UIDs.
+func sanitizeName(name string) string {
+	return regexp.MustCompile(`[^a-zA-Z0-9_-]+`).ReplaceAllString(name, "_")
+}
+
+// writeICS writes events as calendar "ASW Schedule <title>" to <outputDir>/<file>.ics,
+// plus the jCal (.jcal.json) and xCal (.xcs) renderings next to it.
+// UIDs are derived from name, which is not translated, so they stay the same
+// whatever ASW_ICS_LANG is. alarms may be nil; otherwise it returns the
+// VALARMs for one event.
+func writeICS(name, title, file string, events []ScheduleEvent, alarms func(ScheduleEvent) []reminder) error {
+	uidPrefix := sanitizeName(name)
+	return writeICSUIDs(title, file, events, alarms, func(i int, e ScheduleEvent) string {
+		return fmt.Sprintf("%s-%d-%d", uidPrefix, e.Start.Unix(), i)
+	})
+}
+
+// Like writeICS, with the UID of the i-th event given by uid.
+func writeICSUIDs(title, file string, events []ScheduleEvent, alarms func(ScheduleEvent) []reminder, uid func(int, ScheduleEvent) string) error {
 	cal := ics.NewCalendar()
 	cal.SetProductId("-//ASW Schedule Exporter//EN")
-	cal.SetName(fmt.Sprintf("ASW Schedule %s", courseName))
+	cal.SetName(icsText("ics.title", title))
 	cal.SetTzid(tzID)
 
-	// Sanitize for filename and UID.
-	sanitizedName := regexp.MustCompile(`[^a-zA-Z0-9_-]+`).ReplaceAllString(courseName, "_")
-
 	for i, e := range events {
-		ev := cal.AddEvent(fmt.Sprintf("%s-%d-%d", sanitizedName, e.Start.Unix(), i))
+		ev := cal.AddEvent(uid(i, e))
 		ev.SetSummary(e.Summary)
 		if e.Location != "" {
 			ev.SetLocation(e.Location)
@@ -702,15 +1204,93 @@ func generateICS(courseName string, events []ScheduleEvent) error {
 		}
 		ev.SetStartAt(e.Start)
 		ev.SetEndAt(e.End)
+		if e.Status != "" {
+			ev.SetStatus(ics.ObjectStatus(e.Status))
+		}
+
+		if alarms == nil || e.Status == StatusCancelled {
+			continue
+		}
+		for _, r := range alarms(e) {
+			a := ev.AddAlarm()
+			a.SetAction(ics.ActionDisplay)
+			a.SetTrigger("-" + formatICSDuration(r.Before))
+			a.SetProperty(ics.ComponentPropertyDescription, r.Text)
+		}
 	}
 
-	filename := fmt.Sprintf("%s/%s.ics", outputDir, sanitizedName)
-	f, err := os.Create(filename)
-	if err != nil {
+	base := filepath.Join(outputDir, file)
+	if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(base+".ics", []byte(cal.Serialize()), 0644); err != nil {
+		return err
+	}
+
+	// The same calendar as jCal and xCal for JSON/XML based clients.
+	tree := calendarTree(cal)
+	var jcal, xcal bytes.Buffer
+	if err := writeJCal(&jcal, tree); err != nil {
 		return err
 	}
-	defer f.Close()
+	if err := writeXCal(&xcal, tree); err != nil {
+		return err
+	}
+	if err := os.WriteFile(base+".jcal.json", jcal.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(base+".xcs", xcal.Bytes(), 0644)
+}
+
+// Format a duration as an RFC 5545 DURATION value, e.g. P7D, PT15M, P1DT2H.
+func formatICSDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	mins := d / time.Minute
 
-	_, err = f.WriteString(cal.Serialize())
-	return err
+	var b strings.Builder
+	b.WriteString("P")
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if hours > 0 || mins > 0 || days == 0 {
+		b.WriteString("T")
+		if hours > 0 {
+			fmt.Fprintf(&b, "%dH", hours)
+		}
+		if mins > 0 || hours == 0 {
+			fmt.Fprintf(&b, "%dM", mins)
+		}
+	}
+	return b.String()
+}
+
+// Parse lead times like "7d", "1d", "90m", "1h30m" (time.ParseDuration plus a day unit).
+func parseLeadTime(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid lead time %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// Split a comma-separated configuration list, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/url"
+	"path/filepath"
+	"time"
+)
+
+// manifest.json in the public root: every published calendar with its
+// grouping, content hash and subscription URLs, for apps and bots that
+// would otherwise scrape the pages. Built from publishedCalendars, so it
+// does not depend on file names or the page layout.
+
+const manifestVersion = 1
+
+type manifest struct {
+	Version   int                `json:"version"`
+	Generated string             `json:"generated"`
+	SiteURL   string             `json:"siteUrl,omitempty"`
+	Calendars []manifestCalendar `json:"calendars"`
+}
+
+type manifestCalendar struct {
+	File       string `json:"file"` // relative to ics_files/
+	Label      string `json:"label"`
+	Kind       string `json:"kind"`       // class, current, course, exams, module, room, lecturer
+	Aggregated bool   `json:"aggregated"` // several blocks of a class in one calendar
+	Block      string `json:"block,omitempty"`
+	Class      string `json:"class,omitempty"`
+	Events     int    `json:"events"`
+	Hash       string `json:"hash"`
+	Modified   string `json:"modified,omitempty"` // last content change
+	Path       string `json:"path"`               // relative to the public root
+	URL        string `json:"url,omitempty"`      // only with ASW_SITE_URL
+	Webcal     string `json:"webcal,omitempty"`
+}
+
+// base may be nil when ASW_SITE_URL is not set.
+func buildManifest(data scheduleData, cals []calendarEntry, fresh map[string]calendarFreshness, base *url.URL, now time.Time) manifest {
+	m := manifest{Version: manifestVersion, Generated: now.Format(time.RFC3339), Calendars: []manifestCalendar{}}
+	if base != nil {
+		m.SiteURL = base.String()
+	}
+	for _, c := range cals {
+		name := c.File + ".ics"
+		mc := manifestCalendar{
+			File:       name,
+			Label:      c.Name,
+			Kind:       c.Kind,
+			Aggregated: c.Kind == "class" || c.Kind == "current",
+			Block:      data.class(c.Class).Program,
+			Class:      c.Class,
+			Events:     len(c.Events),
+			Hash:       calendarHash(c.Events),
+			Path:       "ics_files/" + name,
+		}
+		if f, ok := fresh[name]; ok && !f.Changed.IsZero() {
+			mc.Modified = f.Changed.Format(time.RFC3339)
+		}
+		if base != nil {
+			mc.URL = calendarURL(base, name)
+			mc.Webcal = webcalURL(mc.URL)
+		}
+		m.Calendars = append(m.Calendars, mc)
+	}
+	return m
+}
+
+func writeManifest(data scheduleData, cals []calendarEntry, now time.Time) error {
+	var base *url.URL
+	if siteURL != "" {
+		b, err := siteBase()
+		if err != nil {
+			return err
+		}
+		base = b
+	}
+	return writeJSONFile(filepath.Join(publicDir, "manifest.json"), buildManifest(data, cals, siteRun.calendars, base, now))
+}
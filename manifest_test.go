@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestBuildManifest(t *testing.T) {
+	start := time.Date(2025, 12, 8, 9, 0, 0, 0, time.UTC)
+	data := newScheduleData()
+	ev := ScheduleEvent{CourseName: "DBWINFO-A04 - 3. Block", Summary: "IBL III", Start: start, End: start.Add(90 * time.Minute), Room: "2.05", Site: "NK"}
+	data.Courses[ev.CourseName] = []ScheduleEvent{ev}
+	data.Classes["DBWINFO-A04"] = []ScheduleEvent{ev}
+	data.Current["DBWINFO-A04"] = []string{ev.CourseName}
+	cals := publishedCalendars(data)
+	changed := time.Date(2025, 12, 1, 6, 0, 0, 0, time.UTC)
+	fresh := map[string]calendarFreshness{"DBWINFO-A04.ics": {Changed: changed, Events: 1}}
+
+	base, _ := url.Parse("https://example.github.io/aswCalender/")
+	m := buildManifest(data, cals, fresh, base, start)
+	byFile := map[string]manifestCalendar{}
+	for _, c := range m.Calendars {
+		byFile[c.File] = c
+	}
+
+	class := byFile["DBWINFO-A04.ics"]
+	if !class.Aggregated || class.Block != "DBWINFO" || class.Class != "DBWINFO-A04" || class.Events != 1 {
+		t.Errorf("class calendar: %+v", class)
+	}
+	if class.Modified != "2025-12-01T06:00:00Z" || class.Hash != calendarHash(data.Classes["DBWINFO-A04"]) {
+		t.Errorf("class freshness: %+v", class)
+	}
+	if class.URL != "https://example.github.io/aswCalender/ics_files/DBWINFO-A04.ics" || class.Webcal != "webcal://example.github.io/aswCalender/ics_files/DBWINFO-A04.ics" {
+		t.Errorf("class URLs: %q %q", class.URL, class.Webcal)
+	}
+
+	if cur := byFile["DBWINFO-A04-current.ics"]; !cur.Aggregated || cur.Kind != "current" || cur.Class != "DBWINFO-A04" {
+		t.Errorf("current calendar: %+v", cur)
+	}
+
+	course := byFile["DBWINFO-A04_-_3_Block.ics"]
+	if course.Aggregated || course.Kind != "course" || course.Label != "DBWINFO-A04 - 3. Block" || course.Modified != "" {
+		t.Errorf("course calendar: %+v", course)
+	}
+
+	room, ok := byFile["rooms/NK-2.05.ics"]
+	if !ok || room.Block != "" || room.Class != "" || room.Path != "ics_files/rooms/NK-2.05.ics" {
+		t.Errorf("room calendar: %+v (files %v)", room, len(byFile))
+	}
+
+	// Without ASW_SITE_URL the URLs are left out.
+	if c := buildManifest(data, cals, fresh, nil, start).Calendars[0]; c.URL != "" || c.Webcal != "" {
+		t.Errorf("URLs without site URL: %+v", c)
+	}
+}
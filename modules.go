@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Per-module calendars: every class calendar is split by the module line
+// parsed in parseEventCell and written to <class>/<module-slug>.ics.
+// Slugs must stay stable across runs, so small spelling variations in the
+// source ("IBL III", "IBL 3", "I.B.L. III (Vorlesung)") map to one slug.
+
+var (
+	// Normalization table for module names that differ by more than the
+	// generic rules in moduleSlug can fix. Keys and values are slugs.
+	// Extend via ASW_MODULE_ALIASES="int-business-law-3=ibl-3;rewe-1=rechnungswesen-1".
+	moduleAliases = mergeAliases(map[string]string{
+		"rewe":                         "rechnungswesen",
+		"int-business-law":             "ibl",
+		"international-business-law":   "ibl",
+		"kosten-und-leistungsrechnung": "klr",
+		"kosten-leistungsrechnung":     "klr",
+		"projekt-management":           "projektmanagement",
+		"projektmgmt":                  "projektmanagement",
+		"wissenschaftliches-arbeiten":  "wiss-arbeiten",
+		"wissenschaftl-arbeiten":       "wiss-arbeiten",
+		"business-english":             "englisch",
+		"english":                      "englisch",
+	}, getenv("ASW_MODULE_ALIASES", ""))
+
+	romanNumerals = map[string]string{
+		"i": "1", "ii": "2", "iii": "3", "iv": "4", "v": "5",
+		"vi": "6", "vii": "7", "viii": "8", "ix": "9", "x": "10",
+	}
+
+	// A single letter after these is a name or variable, not a numeral:
+	// "Mechanik & x" stays "mechanik-und-x".
+	slugConjunctions = map[string]bool{"und": true, "oder": true, "and": true, "or": true}
+
+	slugBracketRe = regexp.MustCompile(`\s*[(\[].*?[)\]]`)
+	slugInvalidRe = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// moduleCalendar is one module's share of a class calendar.
+type moduleCalendar struct {
+	Slug   string
+	Name   string // most frequent original spelling
+	Events []ScheduleEvent
+}
+
+// Parse "from=to;from=to" pairs on top of the built-in table.
+func mergeAliases(base map[string]string, extra string) map[string]string {
+	for _, pair := range strings.Split(extra, ";") {
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		base[moduleSlug(from, nil)] = moduleSlug(to, nil)
+	}
+	return base
+}
+
+// Derive a stable filename slug from a module name:
+// lowercase, umlauts folded, bracket notes dropped, punctuation collapsed
+// to hyphens, trailing Roman numerals turned into digits ("III" -> "3";
+// a single letter only when it does not follow "und" or "oder"),
+// then looked up in the alias table (with and without the trailing number).
+func moduleSlug(name string, aliases map[string]string) string {
+	s := foldGerman(name)
+	s = slugBracketRe.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, "&", " und ")
+
+	// "I.B.L." -> "ibl" before punctuation becomes a separator.
+	s = regexp.MustCompile(`\b([a-z])\.(?:([a-z])\.)+`).ReplaceAllStringFunc(s, func(m string) string {
+		return strings.ReplaceAll(m, ".", "")
+	})
+
+	s = strings.Trim(slugInvalidRe.ReplaceAllString(s, "-"), "-")
+
+	parts := strings.Split(s, "-")
+	if n := len(parts); n > 1 {
+		if arabic, ok := romanNumerals[parts[n-1]]; ok && (len(parts[n-1]) > 1 || !slugConjunctions[parts[n-2]]) {
+			parts[n-1] = arabic
+		}
+	}
+	s = strings.Join(parts, "-")
+
+	if to, ok := aliases[s]; ok {
+		return to
+	}
+
+	// Alias the stem and keep the number: "rewe-2" -> "rechnungswesen-2".
+	if n := len(parts); n > 1 && isDigits(parts[n-1]) {
+		stem := strings.Join(parts[:n-1], "-")
+		if to, ok := aliases[stem]; ok {
+			return to + "-" + parts[n-1]
+		}
+	}
+
+	return s
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Group a class's events by module slug. Events without a module are left out.
+// The result is sorted by slug so files and site listings are deterministic.
+func moduleGroups(events []ScheduleEvent) []moduleCalendar {
+	bySlug := map[string]*moduleCalendar{}
+	spellings := map[string]map[string]int{}
+
+	for _, e := range events {
+		if e.Module == "" {
+			continue
+		}
+		slug := moduleSlug(e.Module, moduleAliases)
+		if slug == "" {
+			continue
+		}
+
+		mc, ok := bySlug[slug]
+		if !ok {
+			mc = &moduleCalendar{Slug: slug}
+			bySlug[slug] = mc
+			spellings[slug] = map[string]int{}
+		}
+		mc.Events = append(mc.Events, e)
+		spellings[slug][e.Module]++
+	}
+
+	out := make([]moduleCalendar, 0, len(bySlug))
+	for slug, mc := range bySlug {
+		mc.Name = mostFrequent(spellings[slug])
+		out = append(out, *mc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Slug < out[j].Slug })
+
+	return out
+}
+
+// Pick the most frequent key; ties go to the alphabetically first for stability.
+func mostFrequent(counts map[string]int) string {
+	best, bestN := "", 0
+	for k, n := range counts {
+		if n > bestN || (n == bestN && k < best) {
+			best, bestN = k, n
+		}
+	}
+	return best
+}
+
+// Relative file (without .ics) of a module calendar: <class>/<slug>.
+func moduleFile(classKey, slug string) string {
+	return sanitizeName(classKey) + "/" + slug
+}
+
+// Write one calendar per module of a class.
+func generateModuleCalendars(classKey string, events []ScheduleEvent) error {
+	for _, mc := range moduleGroups(events) {
+		name := fmt.Sprintf("%s %s", classKey, mc.Name)
+		if err := writeFeed(name, moduleFile(classKey, mc.Slug), classKey, mc.Events); err != nil {
+			return fmt.Errorf("module %s: %w", mc.Slug, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestModuleSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"IBL III", "ibl-3"},
+		{"IBL 3", "ibl-3"},
+		{"I.B.L. III (Vorlesung)", "ibl-3"},
+		{"International Business Law III", "ibl-3"},
+		{"ReWe II", "rechnungswesen-2"},
+		{"Rechnungswesen 2", "rechnungswesen-2"},
+		{"Kosten- und Leistungsrechnung", "klr"},
+		{"Grundlagen der Wirtschaftsinformatik", "grundlagen-der-wirtschaftsinformatik"},
+		{"Übungen zur Statistik", "uebungen-zur-statistik"},
+		{"Mathe I", "mathe-1"},
+		{"Statistik V", "statistik-5"},
+		{`Mechanik & "x"`, "mechanik-und-x"},
+		{"Physik und V", "physik-und-v"},
+		{"Analysis oder I", "analysis-oder-i"},
+		{"Recht und II", "recht-und-2"},
+	}
+
+	for _, tt := range tests {
+		if got := moduleSlug(tt.name, moduleAliases); got != tt.want {
+			t.Errorf("moduleSlug(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Room occupancy report (rooms.json) and the free-room finder page
+// (occupancy.html), built from the room keys of all parsed events.
+
+type occupancyReport struct {
+	Generated string          `json:"generated"`
+	Timezone  string          `json:"timezone"`
+	Rooms     []roomOccupancy `json:"rooms"`
+}
+
+type roomOccupancy struct {
+	Key   string         `json:"key"`
+	Label string         `json:"label"`
+	Days  []dayOccupancy `json:"days"`
+}
+
+type dayOccupancy struct {
+	Date  string         `json:"date"` // 2025-12-08
+	Week  int            `json:"week,omitempty"`
+	Slots []occupiedSlot `json:"slots"`
+}
+
+type occupiedSlot struct {
+	Start   string `json:"start"` // 09:00
+	End     string `json:"end"`
+	Summary string `json:"summary"`
+	Course  string `json:"course"`
+}
+
+// Build the occupancy report. Cancelled events free their room and are left out.
+func buildOccupancy(data scheduleData, now time.Time) occupancyReport {
+	report := occupancyReport{
+		Generated: now.Format(time.RFC3339),
+		Timezone:  tzID,
+		Rooms:     []roomOccupancy{},
+	}
+
+	for _, rc := range roomCalendars(data) {
+		days := map[string]*dayOccupancy{}
+		for _, e := range rc.Events {
+			if e.Status == StatusCancelled {
+				continue
+			}
+			date := e.Start.Format("2006-01-02")
+			d, ok := days[date]
+			if !ok {
+				d = &dayOccupancy{Date: date, Week: e.Week}
+				days[date] = d
+			}
+			d.Slots = append(d.Slots, occupiedSlot{
+				Start:   e.Start.Format("15:04"),
+				End:     e.End.Format("15:04"),
+				Summary: e.Summary,
+				Course:  e.CourseName,
+			})
+		}
+
+		ro := roomOccupancy{Key: rc.Key, Label: rc.Label, Days: []dayOccupancy{}}
+		for _, d := range days {
+			sort.Slice(d.Slots, func(i, j int) bool {
+				if d.Slots[i].Start != d.Slots[j].Start {
+					return d.Slots[i].Start < d.Slots[j].Start
+				}
+				return d.Slots[i].Course < d.Slots[j].Course
+			})
+			ro.Days = append(ro.Days, *d)
+		}
+		sort.Slice(ro.Days, func(i, j int) bool { return ro.Days[i].Date < ro.Days[j].Date })
+
+		if len(ro.Days) > 0 {
+			report.Rooms = append(report.Rooms, ro)
+		}
+	}
+
+	return report
+}
+
+// Write rooms.json into the public folder and occupancy.html into each
+// language folder.
+func renderOccupancy(data scheduleData, locs []locale, now time.Time) error {
+	report := buildOccupancy(data, now)
+
+	js, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(publicDir, "rooms.json"), js, 0644); err != nil {
+		return err
+	}
+
+	for _, l := range locs {
+		if err := renderOccupancyPage(l, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Week sections (Monday date -> room -> date -> slots) for the static grid.
+func occupancyWeeks(report occupancyReport) (map[string]map[string]map[string][]occupiedSlot, []string) {
+	weeks := map[string]map[string]map[string][]occupiedSlot{}
+	for _, r := range report.Rooms {
+		for _, d := range r.Days {
+			t, err := time.Parse("2006-01-02", d.Date)
+			if err != nil {
+				continue
+			}
+			monday := t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7)).Format("2006-01-02")
+			if weeks[monday] == nil {
+				weeks[monday] = map[string]map[string][]occupiedSlot{}
+			}
+			if weeks[monday][r.Label] == nil {
+				weeks[monday][r.Label] = map[string][]occupiedSlot{}
+			}
+			weeks[monday][r.Label][d.Date] = d.Slots
+		}
+	}
+
+	order := make([]string, 0, len(weeks))
+	for w := range weeks {
+		order = append(order, w)
+	}
+	sort.Strings(order)
+	return weeks, order
+}
+
+// Data of occupancy.html (pages/occupancy.html): the free-room finder,
+// which reads rooms.json in the browser, and a grid per week.
+type occupancyPage struct {
+	pageData
+	Weeks []occupancyWeek
+}
+
+type occupancyWeek struct {
+	Monday string // 2025-12-08, for the section anchor
+	Title  string
+	Badge  string
+	Days   []string // Mo 08.12. to Sa 13.12.
+	Rows   []occupancyRow
+}
+
+type occupancyRow struct {
+	Room  string
+	Cells [][]occupiedSlot // one per day
+}
+
+func renderOccupancyPage(l locale, report occupancyReport) error {
+	page := occupancyPage{pageData: l.newPage("occupancy.html", l.T("page.occupancy"), l.T("page.occupancy.sub"))}
+	page.Scripts = []string{"occupancy.js"}
+
+	weeks, order := occupancyWeeks(report)
+	for _, monday := range order {
+		start, _ := time.Parse("2006-01-02", monday)
+		rooms := weeks[monday]
+
+		labels := make([]string, 0, len(rooms))
+		for label := range rooms {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+
+		w := occupancyWeek{
+			Monday: monday,
+			Title:  l.T("occ.week", start.Format(dateFormat)),
+			Badge:  l.T("occ.rooms", len(labels)),
+		}
+		for i := 0; i < 6; i++ {
+			day := start.AddDate(0, 0, i)
+			w.Days = append(w.Days, l.weekday(day.Weekday())+" "+day.Format("02.01."))
+		}
+		for _, label := range labels {
+			row := occupancyRow{Room: label}
+			for i := 0; i < 6; i++ {
+				row.Cells = append(row.Cells, rooms[label][start.AddDate(0, 0, i).Format("2006-01-02")])
+			}
+			w.Rows = append(w.Rows, row)
+		}
+		page.Weeks = append(page.Weeks, w)
+	}
+
+	return renderThemePage("occupancy.html", page)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// samplePage is a strongly simplified sked campus export with one week table.
+const samplePage = `<html><body>
+<div class="w1">Veranstaltungsplan DBBWL-A03</div>
+<div class="w2">7. Studienwoche: 08. - 14.12.2025</div>
+<table>
+  <tr><td class="t">&nbsp;</td><td class="t">Mo, 08.12.2025</td><td class="t">Di, 09.12.2025</td></tr>
+  <tr>
+    <td class="rz1" rowspan="12">9:00</td>
+    <td>&nbsp;</td>
+    <td id="zf160234" class="v" rowspan="18">9:00 - 10:30 Uhr<br>Vorlesung<br>IBL III<br>EXT: Online</td>
+  </tr>
+</table>
+</body></html>`
+
+// parseSamplePage writes page to a temp dir and runs the detail parser on it.
+func parseSamplePage(t *testing.T, page string) []ScheduleEvent {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "block.html")
+	if err := os.WriteFile(path, []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := parseScheduleDetails(ScheduleLink{
+		CourseName: "DBBWL-A03_7_7.Block",
+		URL:        "file://" + path,
+	})
+	if err != nil {
+		t.Fatalf("parseScheduleDetails: %v", err)
+	}
+	return events
+}
+
+func TestParseWeekHeader(t *testing.T) {
+	tests := []struct {
+		text   string
+		number int
+		start  string
+		end    string
+	}{
+		{"7. Studienwoche: 08. - 14.12.2025", 7, "08.12.2025", "14.12.2025"},
+		{"  12. Studienwoche:  27.10. - 02.11.2025 ", 12, "27.10.2025", "02.11.2025"},
+		{"3. Studienwoche: 29.12. – 04.01.2026", 3, "29.12.2025", "04.01.2026"},
+		{"1. Studienwoche: 30.12.2025 - 05.01.2026", 1, "30.12.2025", "05.01.2026"},
+		{"Veranstaltungsplan", 0, "", ""},
+	}
+
+	for _, tt := range tests {
+		w := parseWeekHeader(tt.text)
+		if w.Number != tt.number {
+			t.Errorf("%q: week %d, want %d", tt.text, w.Number, tt.number)
+			continue
+		}
+		if tt.number == 0 {
+			continue
+		}
+		if got := w.Start.Format(dateFormat); got != tt.start {
+			t.Errorf("%q: start %s, want %s", tt.text, got, tt.start)
+		}
+		if got := w.End.Format(dateFormat); got != tt.end {
+			t.Errorf("%q: end %s, want %s", tt.text, got, tt.end)
+		}
+	}
+}
+
+func TestParseScheduleDetailsWeek(t *testing.T) {
+	events := parseSamplePage(t, samplePage)
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+
+	e := events[0]
+	if e.Week != 7 {
+		t.Errorf("week %d, want 7", e.Week)
+	}
+	if want := time.Date(2025, 12, 9, 9, 0, 0, 0, e.Start.Location()); !e.Start.Equal(want) {
+		t.Errorf("start %v, want %v", e.Start, want)
+	}
+	if e.Summary != "IBL III (Vorlesung)" {
+		t.Errorf("summary %q", e.Summary)
+	}
+}
+
+func TestDetectEventStatus(t *testing.T) {
+	tests := []struct {
+		cell   string
+		status EventStatus
+	}{
+		{`<td class="v">9:00 - 10:30 Uhr<br>Vorlesung<br>IBL III<br>NK: 2.05</td>`, StatusConfirmed},
+		{`<td class="v ausfall">9:00 - 10:30 Uhr<br>Vorlesung<br>IBL III</td>`, StatusCancelled},
+		{`<td class="v"><s>9:00 - 10:30 Uhr<br>Vorlesung<br>IBL III</s></td>`, StatusCancelled},
+		{`<td class="v"><span style="text-decoration: line-through">9:00 - 10:30 Uhr</span><br>IBL III</td>`, StatusCancelled},
+		{`<td class="v">9:00 - 10:30 Uhr<br>Vorlesung<br>IBL III<br>entfällt!</td>`, StatusCancelled},
+		{`<td class="v">9:00 - 10:30 Uhr<br>Vorlesung<br>IBL III<br>verschoben</td>`, StatusTentative},
+	}
+
+	for _, tt := range tests {
+		page := `<html><body><div class="w2">7. Studienwoche: 08. - 14.12.2025</div><table>
+<tr><td class="t">&nbsp;</td><td class="t">Mo, 08.12.2025</td></tr>
+<tr><td class="rz1">9:00</td>` + tt.cell + `</tr></table></body></html>`
+
+		events := parseSamplePage(t, page)
+		if len(events) != 1 {
+			t.Errorf("%s: got %d events, want 1", tt.cell, len(events))
+			continue
+		}
+		e := events[0]
+		if e.Status != tt.status {
+			t.Errorf("%s: status %s (%s), want %s", tt.cell, e.Status, e.StatusNote, tt.status)
+		}
+		if e.Summary == "entfällt!" || e.Summary == "verschoben" {
+			t.Errorf("%s: status marker leaked into summary %q", tt.cell, e.Summary)
+		}
+	}
+}
+
+func TestClassifyCellLines(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  cellFields
+	}{
+		{
+			[]string{"9:00 - 10:30 Uhr", "Vorlesung", "IBL III", "EXT: Online"},
+			cellFields{EventType: "Vorlesung", Module: "IBL III", Location: "EXT: Online", Site: "EXT", Room: "Online", IsOnline: true},
+		},
+		{
+			[]string{"13:00 - 16:15 Uhr", "Klausur", "Rechnungswesen - Gruppe A", "Prof. Dr. Meier", "NK: 2.05"},
+			cellFields{EventType: "Klausur", Module: "Rechnungswesen", Group: "Gruppe A", Instructor: "Prof. Dr. Meier", Location: "NK: 2.05", Site: "NK", Room: "2.05"},
+		},
+		{
+			// Unknown type keyword: positional fallback.
+			[]string{"9:00 - 12:15 Uhr", "Blockseminar", "Projektmanagement", "Raum 1.12"},
+			cellFields{EventType: "Blockseminar", Module: "Projektmanagement", Location: "Raum 1.12", Room: "Raum 1.12"},
+		},
+		{
+			// A module with a colon is not a site-prefixed location.
+			[]string{"9:00 - 10:30 Uhr", "Vorlesung", "BWL: Grundlagen", "NK: 2.05"},
+			cellFields{EventType: "Vorlesung", Module: "BWL: Grundlagen", Location: "NK: 2.05", Site: "NK", Room: "2.05"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := classifyCellLines(tt.lines)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q:\n got  %+v\n want %+v", tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestFootnotes(t *testing.T) {
+	page := `<html><body><div class="w2">7. Studienwoche: 08. - 14.12.2025</div><table>
+<tr><td class="t">&nbsp;</td><td class="t">Mo, 08.12.2025</td></tr>
+<tr><td class="rz1">9:00</td><td id="zf1" class="v">9:00 - 10:30 Uhr<br>Vorlesung<br>IBL III [1]<br>NK: 2.05 [2]</td></tr>
+</table>
+<div class="fn">[1] findet online statt<br>[2] Raum geändert</div>
+</body></html>`
+
+	events := parseSamplePage(t, page)
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+
+	e := events[0]
+	if want := []string{"findet online statt", "Raum geändert"}; !reflect.DeepEqual(e.Notes, want) {
+		t.Errorf("notes %q, want %q", e.Notes, want)
+	}
+	if e.Module != "IBL III" {
+		t.Errorf("module %q, marker not stripped", e.Module)
+	}
+	if !e.IsOnline {
+		t.Errorf("footnote should mark the event online")
+	}
+	if e.Status != StatusTentative {
+		t.Errorf("status %s, want %s", e.Status, StatusTentative)
+	}
+	if !strings.Contains(e.Description, "Note: findet online statt") {
+		t.Errorf("description misses note:\n%s", e.Description)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Calendar preview (preview.html?cal=<id>): month, week and agenda views of
+// one calendar, rendered in the browser from the JSON export
+// (ics_files/<id>.json). Markup in pages/preview.html, script in
+// js/preview.js of the theme; no external scripts.
+
+// Link to the preview of a calendar file like "DBWINFO-A04.ics".
+// r=1 tells the page that a reminder variant exists, like data-reminders on
+// the index rows.
+func previewLink(name string) string {
+	q := url.Values{}
+	q.Set("cal", strings.TrimSuffix(name, ".ics"))
+	if hasReminderVariant(name) {
+		q.Set("r", "1")
+	}
+	return "preview.html?" + q.Encode()
+}
+
+// Write preview.html into the language folder.
+func renderPreview(l locale) error {
+	pd := l.newPage("preview.html", l.T("page.preview"), l.T("page.preview.sub"))
+	pd.Scripts = []string{"preview.js"}
+	return renderThemePage("preview.html", pd)
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Minimal QR code encoder (ISO/IEC 18004) for the subscription links on the
+// site: byte mode, error correction level M, versions 1-40, mask chosen by
+// the usual penalty rules. Rendered as SVG, so no image libraries or external
+// services are needed.
+
+type qrCode struct {
+	size    int
+	modules [][]bool // [y][x], true is dark
+	fn      [][]bool // function patterns, not touched by data or masks
+}
+
+// Error correction codewords per block and number of blocks for level M,
+// indexed by version.
+var (
+	qrECCPerBlock = [41]int{-1,
+		10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26,
+		26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28}
+	qrBlocks = [41]int{-1,
+		1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16,
+		17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49}
+)
+
+const qrFormatM = 0 // format bits of level M
+
+// Encode text in the smallest version that fits.
+func encodeQR(text string) (*qrCode, error) {
+	data := []byte(text)
+	for ver := 1; ver <= 40; ver++ {
+		countBits := 8
+		if ver >= 10 {
+			countBits = 16
+		}
+		capacity := qrDataCodewords(ver) * 8
+		if 4+countBits+len(data)*8 > capacity {
+			continue
+		}
+
+		var bb qrBits
+		bb.append(0x4, 4) // byte mode
+		bb.append(len(data), countBits)
+		for _, b := range data {
+			bb.append(int(b), 8)
+		}
+		bb.append(0, min(4, capacity-len(bb))) // terminator
+		bb.append(0, (8-len(bb)%8)%8)
+		for pad := 0xEC; len(bb) < capacity; pad ^= 0xEC ^ 0x11 {
+			bb.append(pad, 8)
+		}
+
+		q := newQRCode(ver)
+		q.drawCodewords(qrInterleave(ver, bb.bytes()))
+		q.applyBestMask()
+		return q, nil
+	}
+	return nil, fmt.Errorf("qr: %d bytes do not fit into a QR code", len(data))
+}
+
+type qrBits []bool
+
+func (b *qrBits) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 == 1)
+	}
+}
+
+func (b qrBits) bytes() []byte {
+	out := make([]byte, (len(b)+7)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return out
+}
+
+// Number of modules available for data and error correction.
+func qrRawModules(ver int) int {
+	n := (16*ver+128)*ver + 64
+	if ver >= 2 {
+		align := ver/7 + 2
+		n -= (25*align-10)*align - 55
+		if ver >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+func qrDataCodewords(ver int) int {
+	return qrRawModules(ver)/8 - qrECCPerBlock[ver]*qrBlocks[ver]
+}
+
+// Split data into blocks, add Reed-Solomon codewords and interleave.
+func qrInterleave(ver int, data []byte) []byte {
+	numBlocks, eccLen := qrBlocks[ver], qrECCPerBlock[ver]
+	raw := qrRawModules(ver) / 8
+	short := numBlocks - raw%numBlocks
+	shortLen := raw / numBlocks
+
+	div := rsDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := range blocks {
+		n := shortLen - eccLen
+		if i >= short {
+			n++
+		}
+		dat := append([]byte(nil), data[k:k+n]...)
+		k += n
+		ecc := rsRemainder(dat, div)
+		if i < short {
+			dat = append(dat, 0) // placeholder, skipped below
+		}
+		blocks[i] = append(dat, ecc...)
+	}
+
+	out := make([]byte, 0, raw)
+	for i := range blocks[0] {
+		for j, b := range blocks {
+			if i != shortLen-eccLen || j >= short {
+				out = append(out, b[i])
+			}
+		}
+	}
+	return out
+}
+
+// Multiply in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// Generator polynomial of the given degree, highest coefficient dropped.
+func rsDivisor(degree int) []byte {
+	out := make([]byte, degree)
+	out[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range out {
+			out[j] = gfMul(out[j], root)
+			if j+1 < len(out) {
+				out[j] ^= out[j+1]
+			}
+		}
+		root = gfMul(root, 2)
+	}
+	return out
+}
+
+func rsRemainder(data, div []byte) []byte {
+	out := make([]byte, len(div))
+	for _, b := range data {
+		factor := b ^ out[0]
+		copy(out, out[1:])
+		out[len(out)-1] = 0
+		for i, d := range div {
+			out[i] ^= gfMul(d, factor)
+		}
+	}
+	return out
+}
+
+// Code of the given version with all function patterns drawn.
+func newQRCode(ver int) *qrCode {
+	size := ver*4 + 17
+	q := &qrCode{size: size, modules: make([][]bool, size), fn: make([][]bool, size)}
+	for i := range q.modules {
+		q.modules[i] = make([]bool, size)
+		q.fn[i] = make([]bool, size)
+	}
+
+	for i := 0; i < size; i++ {
+		q.setFn(6, i, i%2 == 0)
+		q.setFn(i, 6, i%2 == 0)
+	}
+	q.drawFinder(3, 3)
+	q.drawFinder(size-4, 3)
+	q.drawFinder(3, size-4)
+
+	pos := qrAlignmentPositions(ver)
+	last := len(pos) - 1
+	for i, y := range pos {
+		for j, x := range pos {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue // overlaps a finder
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.setFn(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	q.drawFormat(0) // reserve the area, redrawn with the chosen mask
+	if ver >= 7 {
+		rem := ver
+		for i := 0; i < 12; i++ {
+			rem = rem<<1 ^ (rem>>11)*0x1F25
+		}
+		bits := ver<<12 | rem
+		for i := 0; i < 18; i++ {
+			a, b := size-11+i%3, i/3
+			q.setFn(a, b, bits>>i&1 == 1)
+			q.setFn(b, a, bits>>i&1 == 1)
+		}
+	}
+	return q
+}
+
+func qrAlignmentPositions(ver int) []int {
+	if ver == 1 {
+		return nil
+	}
+	n := ver/7 + 2
+	step := 26
+	if ver != 32 {
+		step = (ver*4 + n*2 + 1) / (n*2 - 2) * 2
+	}
+	out := make([]int, n)
+	out[0] = 6
+	for i, p := n-1, ver*4+10; i > 0; i, p = i-1, p-step {
+		out[i] = p
+	}
+	return out
+}
+
+func (q *qrCode) setFn(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.fn[y][x] = true
+}
+
+func (q *qrCode) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= q.size || y >= q.size {
+				continue
+			}
+			d := max(abs(dx), abs(dy))
+			q.setFn(x, y, d != 2 && d != 4)
+		}
+	}
+}
+
+// Format information: level M and the mask, BCH protected, in both copies.
+func qrFormatBits(mask int) int {
+	data := qrFormatM<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (q *qrCode) drawFormat(mask int) {
+	bits := qrFormatBits(mask)
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+	for i := 0; i <= 5; i++ {
+		q.setFn(8, i, bit(i))
+	}
+	q.setFn(8, 7, bit(6))
+	q.setFn(8, 8, bit(7))
+	q.setFn(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFn(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		q.setFn(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFn(8, q.size-15+i, bit(i))
+	}
+	q.setFn(8, q.size-8, true) // dark module
+}
+
+// Place the codewords in the zigzag order, two columns at a time from the right.
+func (q *qrCode) drawCodewords(data []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // skip the vertical timing pattern
+		}
+		for vert := 0; vert < q.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert // upward
+				}
+				if !q.fn[y][x] && i < len(data)*8 {
+					q.modules[y][x] = data[i>>3]>>(7-i&7)&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+func qrMask(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// XOR a mask over the data modules; applying it twice undoes it.
+func (q *qrCode) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if !q.fn[y][x] && qrMask(mask, x, y) {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+func (q *qrCode) applyBestMask() {
+	best, bestScore := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormat(mask)
+		if s := q.penalty(); bestScore < 0 || s < bestScore {
+			best, bestScore = mask, s
+		}
+		q.applyMask(mask)
+	}
+	q.applyMask(best)
+	q.drawFormat(best)
+}
+
+// Penalty score of the current modules; lower scans better.
+func (q *qrCode) penalty() int {
+	n := q.size
+	score, dark := 0, 0
+	at := func(x, y int, vertical bool) bool {
+		if vertical {
+			return q.modules[x][y]
+		}
+		return q.modules[y][x]
+	}
+	finderLike := []bool{true, false, true, true, true, false, true}
+
+	for _, vertical := range []bool{false, true} {
+		for y := 0; y < n; y++ {
+			run := 1
+			for x := 1; x <= n; x++ {
+				if x < n && at(x, y, vertical) == at(x-1, y, vertical) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += 3 + run - 5
+				}
+				run = 1
+			}
+			// 1:1:3:1:1 finder-like patterns with four light modules on one side.
+			for x := 0; x+7 <= n; x++ {
+				match := true
+				for k, v := range finderLike {
+					if at(x+k, y, vertical) != v {
+						match = false
+						break
+					}
+				}
+				if match && (q.lightRun(x-4, x, y, vertical) || q.lightRun(x+7, x+11, y, vertical)) {
+					score += 40
+				}
+			}
+		}
+	}
+
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			c := q.modules[y][x]
+			if c {
+				dark++
+			}
+			if x+1 < n && y+1 < n && c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+				score += 3
+			}
+		}
+	}
+
+	percent := dark * 100 / (n * n)
+	return score + abs(percent-50)/5*10
+}
+
+// Report whether modules from..to-1 of a row (or column) are light; modules
+// outside the symbol count as light.
+func (q *qrCode) lightRun(from, to, y int, vertical bool) bool {
+	for x := from; x < to; x++ {
+		if x < 0 || x >= q.size {
+			continue
+		}
+		if (vertical && q.modules[x][y]) || (!vertical && q.modules[y][x]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SVG with a four-module quiet zone, one path of horizontal runs.
+func (q *qrCode) svg() string {
+	const quiet = 4
+	n := q.size + 2*quiet
+	var path strings.Builder
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; {
+			if !q.modules[y][x] {
+				x++
+				continue
+			}
+			start := x
+			for x < q.size && q.modules[y][x] {
+				x++
+			}
+			path.WriteString("M" + strconv.Itoa(start+quiet) + " " + strconv.Itoa(y+quiet) + "h" + strconv.Itoa(x-start) + "v1h-" + strconv.Itoa(x-start) + "z")
+		}
+	}
+	dim := strconv.Itoa(n)
+	return "<svg xmlns='http://www.w3.org/2000/svg' viewBox='0 0 " + dim + " " + dim + "' shape-rendering='crispEdges'>" +
+		"<rect width='" + dim + "' height='" + dim + "' fill='#fff'/>" +
+		"<path fill='#000' d='" + path.String() + "'/></svg>\n"
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Reed-Solomon example of the QR specification tutorials: "HELLO WORLD" as
+// version 1-M in alphanumeric mode.
+func TestReedSolomon(t *testing.T) {
+	data := []byte{0x20, 0x5B, 0x0B, 0x78, 0xD1, 0x72, 0xDC, 0x4D, 0x43, 0x40, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	want := []byte{0xC4, 0x23, 0x27, 0x77, 0xEB, 0xD7, 0xE7, 0xE2, 0x5D, 0x17}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("ecc = % X, want % X", got, want)
+	}
+}
+
+func TestQRFormatAndVersionBits(t *testing.T) {
+	// Level M format strings from the specification table.
+	for mask, want := range []int{0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0} {
+		if got := qrFormatBits(mask); got != want {
+			t.Errorf("format bits mask %d = %015b, want %015b", mask, got, want)
+		}
+	}
+
+	// Version 7 information 000111 110010010100, read from the top right block.
+	q := newQRCode(7)
+	bits := 0
+	for i := 0; i < 18; i++ {
+		if q.modules[i/3][q.size-11+i%3] {
+			bits |= 1 << i
+		}
+	}
+	if bits != 0x07C94 {
+		t.Errorf("version 7 bits = %018b", bits)
+	}
+}
+
+func TestQRAlignmentPositions(t *testing.T) {
+	for ver, want := range map[int]string{1: "", 2: "6 18", 7: "6 22 38", 22: "6 26 50 74 98", 32: "6 34 60 86 112 138", 40: "6 30 58 86 114 142 170"} {
+		if got := sprintInts(qrAlignmentPositions(ver)); got != want {
+			t.Errorf("version %d: alignment %q, want %q", ver, got, want)
+		}
+	}
+}
+
+func sprintInts(v []int) string {
+	var b strings.Builder
+	for i, n := range v {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strconvI(n))
+	}
+	return b.String()
+}
+
+// Read the symbol back: format bits, mask, codewords, blocks, and check the
+// error correction and the payload.
+func TestQRRoundTrip(t *testing.T) {
+	for _, text := range []string{
+		"https://example.github.io/aswCalender/ics_files/DBWINFO-A04.ics",
+		"webcal://example.github.io/aswCalender/ics_files/reminders/DBING-01-2024_-_4_Blockphase.ics",
+		"x",
+		strings.Repeat("ASW", 120), // version 10+, 16-bit length, several block sizes
+	} {
+		q, err := encodeQR(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ver := (q.size - 17) / 4
+
+		format := 0
+		for i := 0; i <= 5; i++ {
+			if q.modules[i][8] {
+				format |= 1 << i
+			}
+		}
+		for i, p := range [][2]int{{8, 7}, {8, 8}, {7, 8}} {
+			if q.modules[p[1]][p[0]] {
+				format |= 1 << (6 + i)
+			}
+		}
+		for i := 9; i < 15; i++ {
+			if q.modules[8][14-i] {
+				format |= 1 << i
+			}
+		}
+		mask := -1
+		for m := 0; m < 8; m++ {
+			if qrFormatBits(m) == format {
+				mask = m
+			}
+		}
+		if mask < 0 {
+			t.Fatalf("%q: invalid format bits %015b", text, format)
+		}
+
+		q.applyMask(mask)
+		var bits qrBits
+		for right := q.size - 1; right >= 1; right -= 2 {
+			if right == 6 {
+				right = 5
+			}
+			for vert := 0; vert < q.size; vert++ {
+				for j := 0; j < 2; j++ {
+					x, y := right-j, vert
+					if (right+1)&2 == 0 {
+						y = q.size - 1 - vert
+					}
+					if !q.fn[y][x] {
+						bits = append(bits, q.modules[y][x])
+					}
+				}
+			}
+		}
+		raw := bits.bytes()[:qrRawModules(ver)/8]
+
+		// De-interleave into blocks and check each block's error correction.
+		numBlocks, eccLen := qrBlocks[ver], qrECCPerBlock[ver]
+		short := numBlocks - len(raw)%numBlocks
+		dataLen := len(raw)/numBlocks - eccLen
+		blocks := make([][]byte, numBlocks)
+		k := 0
+		for i := 0; i < dataLen+1; i++ {
+			for j := range blocks {
+				if i < dataLen || j >= short {
+					blocks[j] = append(blocks[j], raw[k])
+					k++
+				}
+			}
+		}
+		for i := 0; i < eccLen; i++ {
+			for j := range blocks {
+				blocks[j] = append(blocks[j], raw[k])
+				k++
+			}
+		}
+		var data []byte
+		for j := range blocks {
+			n := len(blocks[j]) - eccLen
+			if got := rsRemainder(blocks[j][:n], rsDivisor(eccLen)); !bytes.Equal(got, blocks[j][n:]) {
+				t.Errorf("%q: block %d error correction mismatch", text, j)
+			}
+			data = append(data, blocks[j][:n]...)
+		}
+
+		countBytes := 1
+		if ver >= 10 {
+			countBytes = 2
+		}
+		var payload qrBits
+		for _, b := range data {
+			payload.append(int(b), 8)
+		}
+		payload = payload[4:] // byte mode indicator
+		n := 0
+		for _, bit := range payload[:countBytes*8] {
+			n <<= 1
+			if bit {
+				n |= 1
+			}
+		}
+		if got := string(payload[countBytes*8:].bytes()[:n]); got != text {
+			t.Errorf("decoded %q, want %q", got, text)
+		}
+	}
+}
+
+func TestQRTooLong(t *testing.T) {
+	if _, err := encodeQR(strings.Repeat("x", 3000)); err == nil {
+		t.Error("expected an error for 3000 bytes")
+	}
+}
+
+func TestCalendarURL(t *testing.T) {
+	for _, site := range []string{"https://example.github.io/aswCalender", "https://example.github.io/aswCalender/"} {
+		base, err := url.Parse(site)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "https://example.github.io/aswCalender/ics_files/rooms/NK%201.12.ics"
+		if got := calendarURL(base, "rooms/NK 1.12.ics"); got != want {
+			t.Errorf("calendarURL(%q) = %q, want %q", site, got, want)
+		}
+	}
+	if got := qrFile("reminders/DBING-01.ics", "webcal"); got != "reminders/DBING-01.webcal.svg" {
+		t.Errorf("qrFile = %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Reminder policy for VALARM components.
+//
+// Rules are read from ASW_REMINDERS as a semicolon-separated list:
+//
+//	[<class>:]<kind>=<lead>[,<lead>...]
+//
+// <kind> is one of the built-in categories "lecture" (fallback for everything),
+// "onsite" (has a physical room), "online" and "exam", or an event type such as
+// "Übung". Leads use d/h/m units. An empty lead list disables reminders for that
+// kind. ASW_REMINDERS=off skips the "with reminders" feed variants; the exam
+// calendars keep their default alarms.
+//
+// Examples:
+//
+//	ASW_REMINDERS="lecture=10m; DBING-01:onsite=90m; Klausur=14d,1d"
+var (
+	reminderRules    = getenv("ASW_REMINDERS", "")
+	reminderVariants = !strings.EqualFold(reminderRules, "off")
+
+	reminders = loadReminderPolicy()
+)
+
+// reminder is one VALARM: how long before the event and what it says.
+type reminder struct {
+	Before time.Duration
+	Text   string
+}
+
+// reminderPolicy maps "<kind>" and "<class>:<kind>" keys to lead times.
+type reminderPolicy map[string][]time.Duration
+
+// Parse the reminder policy. Built-in defaults come first, ASW_REMINDERS
+// overrides them rule by rule. Invalid rules are skipped with a warning.
+func loadReminderPolicy() reminderPolicy {
+	p := reminderPolicy{}
+
+	rules := reminderRules
+	if !reminderVariants {
+		rules = ""
+	}
+
+	defaults := "lecture=15m; onsite=60m; exam=" + examReminders
+	for _, src := range []string{defaults, rules} {
+		for _, rule := range strings.Split(src, ";") {
+			rule = strings.TrimSpace(rule)
+			if rule == "" {
+				continue
+			}
+
+			key, leads, err := parseReminderRule(rule)
+			if err != nil {
+				log.Printf("warning: ignoring reminder rule %q: %v", rule, err)
+				continue
+			}
+			p[key] = leads
+		}
+	}
+
+	return p
+}
+
+func parseReminderRule(rule string) (string, []time.Duration, error) {
+	key, leads, ok := strings.Cut(rule, "=")
+	if !ok {
+		return "", nil, fmt.Errorf("missing '='")
+	}
+
+	var ds []time.Duration
+	for _, l := range splitList(leads) {
+		d, err := parseLeadTime(l)
+		if err != nil {
+			return "", nil, err
+		}
+		ds = append(ds, d)
+	}
+	return policyKey(key), ds, nil
+}
+
+// Normalize "DBING-01:Übung" to "DBING-01:uebung"; the class part keeps its case.
+func policyKey(key string) string {
+	class, kind, ok := strings.Cut(strings.TrimSpace(key), ":")
+	if !ok {
+		return foldGerman(strings.TrimSpace(class))
+	}
+	return strings.TrimSpace(class) + ":" + foldGerman(strings.TrimSpace(kind))
+}
+
+// Event kinds in order of precedence: the literal type beats the categories.
+func reminderKinds(e ScheduleEvent) []string {
+	var kinds []string
+	if e.EventType != "" {
+		kinds = append(kinds, foldGerman(e.EventType))
+	}
+	if isExamEvent(e) {
+		kinds = append(kinds, "exam")
+	}
+	if e.IsOnline {
+		kinds = append(kinds, "online")
+	} else if e.Room != "" {
+		kinds = append(kinds, "onsite")
+	}
+	return append(kinds, "lecture")
+}
+
+// Reminders returns the alarms for one event of a class.
+// Class-specific rules win over general ones for the same kind.
+func (p reminderPolicy) Reminders(classKey string, e ScheduleEvent) []reminder {
+	for _, kind := range reminderKinds(e) {
+		leads, ok := p[classKey+":"+kind]
+		if !ok {
+			leads, ok = p[kind]
+		}
+		if !ok {
+			continue
+		}
+
+		out := make([]reminder, 0, len(leads))
+		for _, d := range leads {
+			out = append(out, reminder{Before: d, Text: reminderText(kind, e)})
+		}
+		return out
+	}
+	return nil
+}
+
+// Wording depends on what the reminder is for: on-site events are about
+// getting there, exams about preparing.
+func reminderText(kind string, e ScheduleEvent) string {
+	switch {
+	case kind == "exam":
+		return icsText("ics.reminder.exam", e.Summary)
+	case kind == "onsite" && e.Location != "":
+		return icsText("ics.reminder.travel", e.Location, e.Summary)
+	default:
+		return e.Summary
+	}
+}
+
+// Alarm callback for writeICS, bound to one class.
+func (p reminderPolicy) forClass(classKey string) func(ScheduleEvent) []reminder {
+	return func(e ScheduleEvent) []reminder {
+		return p.Reminders(classKey, e)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// runReport summarizes one run. It is published as report.json next to the
+// site so problems are visible without digging through the Actions log.
+type runReport struct {
+	Generated time.Time         `json:"generated"`
+	Links     int               `json:"links"`
+	Courses   int               `json:"courses"`
+	Classes   int               `json:"classes"`
+	Events    int               `json:"events"`
+	Failed    map[string]string `json:"failed"`
+	Stale     []string          `json:"stale"` // courses not fetched successfully within ASW_STALE_AFTER
+	// Course names without a recognizable program, listed under "Other"
+	Unrecognized []string   `json:"unrecognized"`
+	Conflicts    []conflict `json:"conflicts"`
+}
+
+func buildRunReport(data scheduleData, now time.Time) runReport {
+	r := runReport{
+		Generated: now,
+		Links:     data.Links,
+		Courses:   len(data.Courses),
+		Classes:   len(data.Classes),
+		Failed:    data.Failed,
+		Conflicts: findConflicts(data),
+	}
+	for _, evs := range data.Classes {
+		r.Events += len(evs)
+	}
+	if r.Failed == nil {
+		r.Failed = map[string]string{}
+	}
+	r.Stale = []string{}
+	r.Unrecognized = data.unrecognized()
+	if r.Unrecognized == nil {
+		r.Unrecognized = []string{}
+	}
+	if r.Conflicts == nil {
+		r.Conflicts = []conflict{}
+	}
+	return r
+}
+
+// Write report.json and the conflicts page of each language, and log the
+// headline numbers.
+func writeRunReport(data scheduleData, locs []locale, stale []string, now time.Time) (runReport, error) {
+	r := buildRunReport(data, now)
+	if stale != nil {
+		r.Stale = stale
+	}
+
+	if len(r.Conflicts) > 0 {
+		kinds := map[string]int{}
+		for _, c := range r.Conflicts {
+			kinds[c.Kind]++
+		}
+		names := make([]string, 0, len(kinds))
+		for k := range kinds {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			log.Printf("warning: %d %s conflicts detected, see conflicts.html", kinds[k], k)
+		}
+	}
+
+	js, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return r, err
+	}
+	if err := os.WriteFile(filepath.Join(publicDir, "report.json"), js, 0644); err != nil {
+		return r, err
+	}
+
+	for _, l := range locs {
+		if err := renderConflictsPage(l, r.Conflicts); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Timetables per room and per lecturer, inverted from all class calendars:
+// rooms/<room>.ics (e.g. rooms/NK-2.05.ics) and lecturers/<slug>.ics.
+
+// resourceCalendar is the set of events sharing one room or one lecturer.
+type resourceCalendar struct {
+	Key    string // stable file key, e.g. "NK-2.05" or "meier-hans"
+	Label  string // display name, e.g. "NK 2.05" or "Prof. Dr. Hans Meier"
+	File   string // relative file without .ics
+	Events []ScheduleEvent
+}
+
+var (
+	roomPrefixRe      = regexp.MustCompile(`(?i)^(raum|r\.)\s*`)
+	instructorSplitRe = regexp.MustCompile(`\s*(?:/|;|&|\bund\b)\s*`)
+	academicTitleRe   = regexp.MustCompile(`(?i)\b(prof|dr|dipl|hr|fr|herr|frau|m\.?\s?a|m\.?\s?sc|b\.?\s?a|b\.?\s?sc|ing|rer|nat|pol|oec|h\.?\s?c|phil|jur|mba|ll\.?\s?m)\b\.?(-\S+)?`)
+)
+
+// Normalized room key for an event, or "" for online events and events
+// without a room. "NK: 2.05" -> "NK-2.05", "Raum 1.12" -> "1.12".
+func roomKey(e ScheduleEvent) string {
+	if e.IsOnline || e.Room == "" {
+		return ""
+	}
+
+	room := strings.TrimSpace(roomPrefixRe.ReplaceAllString(e.Room, ""))
+	if room == "" {
+		return ""
+	}
+
+	key := room
+	if e.Site != "" {
+		key = e.Site + "-" + room
+	}
+	return strings.Trim(regexp.MustCompile(`[^A-Za-z0-9._-]+`).ReplaceAllString(key, "-"), "-")
+}
+
+// Display label for a room key: "NK-2.05" -> "NK 2.05".
+func roomLabel(e ScheduleEvent) string {
+	room := strings.TrimSpace(roomPrefixRe.ReplaceAllString(e.Room, ""))
+	if e.Site != "" {
+		return e.Site + " " + room
+	}
+	return room
+}
+
+// Split the instructor line into individual names ("Prof. Dr. Meier / Dr. Schulz").
+func instructorNames(e ScheduleEvent) []string {
+	var names []string
+	for _, n := range instructorSplitRe.Split(e.Instructor, -1) {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// Slug for a lecturer: titles and salutations removed, so "Prof. Dr. Meier"
+// and "Dr. Meier" end up in the same timetable.
+func lecturerSlug(name string) string {
+	s := academicTitleRe.ReplaceAllString(name, " ")
+	return moduleSlug(s, nil)
+}
+
+// Invert the class calendars into per-room timetables, sorted by key.
+func roomCalendars(data scheduleData) []resourceCalendar {
+	return invertCalendars(data, "rooms", func(e ScheduleEvent) []resourceRef {
+		key := roomKey(e)
+		if key == "" {
+			return nil
+		}
+		return []resourceRef{{key, roomLabel(e)}}
+	})
+}
+
+// Invert the class calendars into per-lecturer timetables, sorted by key.
+func lecturerCalendars(data scheduleData) []resourceCalendar {
+	return invertCalendars(data, "lecturers", func(e ScheduleEvent) []resourceRef {
+		var refs []resourceRef
+		for _, n := range instructorNames(e) {
+			if slug := lecturerSlug(n); slug != "" {
+				refs = append(refs, resourceRef{slug, n})
+			}
+		}
+		return refs
+	})
+}
+
+type resourceRef struct {
+	Key   string
+	Label string
+}
+
+// A joint lecture of several classes appears once in every class calendar.
+// It is kept once per resource, identified by time, title and location.
+func invertCalendars(data scheduleData, dir string, refs func(ScheduleEvent) []resourceRef) []resourceCalendar {
+	byKey := map[string]*resourceCalendar{}
+	labels := map[string]map[string]int{}
+	seen := map[string]map[string]bool{}
+
+	// Iterate classes in order so event order inside each calendar is stable.
+	classKeys := make([]string, 0, len(data.Classes))
+	for k := range data.Classes {
+		classKeys = append(classKeys, k)
+	}
+	sort.Strings(classKeys)
+
+	for _, classKey := range classKeys {
+		for _, e := range data.Classes[classKey] {
+			for _, r := range refs(e) {
+				rc, ok := byKey[r.Key]
+				if !ok {
+					rc = &resourceCalendar{Key: r.Key, File: dir + "/" + r.Key}
+					byKey[r.Key] = rc
+					labels[r.Key] = map[string]int{}
+					seen[r.Key] = map[string]bool{}
+				}
+				labels[r.Key][r.Label]++
+				id := sharedEventKey(e)
+				if seen[r.Key][id] {
+					continue
+				}
+				seen[r.Key][id] = true
+				rc.Events = append(rc.Events, e)
+			}
+		}
+	}
+
+	out := make([]resourceCalendar, 0, len(byKey))
+	for key, rc := range byKey {
+		rc.Label = mostFrequent(labels[key])
+		sort.SliceStable(rc.Events, func(i, j int) bool { return rc.Events[i].Start.Before(rc.Events[j].Start) })
+		out = append(out, *rc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+
+	return out
+}
+
+// Key identifying the same event across class calendars.
+func sharedEventKey(e ScheduleEvent) string {
+	return e.Start.UTC().Format(time.RFC3339) + "|" + e.End.UTC().Format(time.RFC3339) + "|" + e.Summary + "|" + e.Location
+}
+
+// Write the room and lecturer timetables. Rooms get no reminder variant,
+// lecturers do, like the class calendars.
+func generateResourceCalendars(data scheduleData) error {
+	for _, rc := range roomCalendars(data) {
+		if err := writeICS("Room "+rc.Label, icsText("ics.room", rc.Label), rc.File, rc.Events, nil); err != nil {
+			return fmt.Errorf("room %s: %w", rc.Key, err)
+		}
+	}
+	for _, rc := range lecturerCalendars(data) {
+		if err := writeFeed(rc.Label, rc.File, "", rc.Events); err != nil {
+			return fmt.Errorf("lecturer %s: %w", rc.Key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInvertCalendarsJointLecture(t *testing.T) {
+	start := time.Date(2025, 12, 9, 9, 0, 0, 0, time.UTC)
+	joint := func(course string) ScheduleEvent {
+		return ScheduleEvent{
+			CourseName: course, Summary: "IBL III", Location: "NK: 2.05",
+			Site: "NK", Room: "2.05", Instructor: "Dr. Meier",
+			Start: start, End: start.Add(90 * time.Minute),
+		}
+	}
+
+	data := newScheduleData()
+	data.Classes["A"] = []ScheduleEvent{joint("A - 3. Block")}
+	data.Classes["B"] = []ScheduleEvent{
+		joint("B - 3. Block"),
+		{CourseName: "B - 3. Block", Summary: "Marketing", Location: "NK: 2.05", Site: "NK", Room: "2.05",
+			Instructor: "Dr. Meier", Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour)},
+	}
+
+	rooms := roomCalendars(data)
+	if len(rooms) != 1 || rooms[0].Key != "NK-2.05" {
+		t.Fatalf("rooms = %+v, want one NK-2.05 calendar", rooms)
+	}
+	if n := len(rooms[0].Events); n != 2 {
+		t.Errorf("room events = %d, want 2", n)
+	}
+
+	lecturers := lecturerCalendars(data)
+	if len(lecturers) != 1 || len(lecturers[0].Events) != 2 {
+		t.Errorf("lecturers = %+v, want one calendar with 2 events", lecturers)
+	}
+
+	occ := buildOccupancy(data, start)
+	if len(occ.Rooms) != 1 || len(occ.Rooms[0].Days) != 1 || len(occ.Rooms[0].Days[0].Slots) != 2 {
+		t.Errorf("occupancy = %+v, want 2 slots", occ.Rooms)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Site search: search.json is built with the site and lists every calendar
+// with the course names, class key, modules, lecturers and rooms it covers.
+// The search box (js/search.js in the theme) loads it on first use and filters in the
+// browser, so no server is needed.
+
+type searchEntry struct {
+	ID          string   `json:"id"` // calendar file without .ics
+	Kind        string   `json:"kind"`
+	Name        string   `json:"name"`
+	Class       string   `json:"class,omitempty"`
+	Page        string   `json:"page"` // listing page with the calendar row, incl. anchor
+	Preview     string   `json:"preview"`
+	Courses     []string `json:"courses,omitempty"`
+	Modules     []string `json:"modules,omitempty"`
+	Instructors []string `json:"instructors,omitempty"`
+	Rooms       []string `json:"rooms,omitempty"`
+}
+
+// Listing page of each calendar kind.
+var searchPages = map[string]string{
+	"class":    "index.html",
+	"current":  "index.html",
+	"module":   "index.html",
+	"course":   "all.html",
+	"exams":    "exams.html",
+	"room":     "rooms.html",
+	"lecturer": "lecturers.html",
+}
+
+var anchorInvalidRe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// HTML id of a calendar row, e.g. "cal-DBWINFO-A04-ibl-3" for "DBWINFO-A04/ibl-3.ics".
+func rowAnchor(name string) string {
+	return "cal-" + anchorInvalidRe.ReplaceAllString(strings.TrimSuffix(name, ".ics"), "-")
+}
+
+func buildSearchIndex(data scheduleData) []searchEntry {
+	out := []searchEntry{}
+	for _, c := range publishedCalendars(data) {
+		name := c.File + ".ics"
+		e := searchEntry{
+			ID:      c.File,
+			Kind:    c.Kind,
+			Name:    c.Name,
+			Class:   c.Class,
+			Page:    searchPages[c.Kind] + "#" + rowAnchor(name),
+			Preview: previewLink(name),
+		}
+
+		courses, modules, instructors, rooms := map[string]bool{}, map[string]bool{}, map[string]bool{}, map[string]bool{}
+		for _, ev := range c.Events {
+			courses[ev.CourseName] = true
+			if ev.Module != "" {
+				modules[ev.Module] = true
+			}
+			for _, n := range instructorNames(ev) {
+				instructors[n] = true
+			}
+			if roomKey(ev) != "" {
+				rooms[roomLabel(ev)] = true
+			}
+		}
+		e.Courses = sortedSet(courses)
+		e.Modules = sortedSet(modules)
+		e.Instructors = sortedSet(instructors)
+		e.Rooms = sortedSet(rooms)
+
+		out = append(out, e)
+	}
+	return out
+}
+
+func sortedSet(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Write search.json into the public folder.
+func writeSearchIndex(data scheduleData) error {
+	return writeJSONFile(filepath.Join(publicDir, "search.json"), buildSearchIndex(data))
+}
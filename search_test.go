@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildSearchIndex(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 12, 8, h, 0, 0, 0, time.UTC) }
+	course := "DBWINFO-A04 - 3. Block"
+	data := scheduleData{
+		Courses: map[string][]ScheduleEvent{course: {
+			{CourseName: course, Summary: "IBL III (Vorlesung)", Module: "IBL III", Instructor: "Prof. Dr. Meier", Room: "2.05", Start: at(9), End: at(10)},
+		}},
+	}
+	data.Classes = map[string][]ScheduleEvent{"DBWINFO-A04": data.Courses[course]}
+
+	byKind := map[string]searchEntry{}
+	for _, e := range buildSearchIndex(data) {
+		byKind[e.Kind] = e
+	}
+
+	class := byKind["class"]
+	if class.Page != "index.html#cal-DBWINFO-A04" {
+		t.Errorf("class page = %q", class.Page)
+	}
+	if len(class.Modules) != 1 || class.Modules[0] != "IBL III" || len(class.Instructors) != 1 || len(class.Rooms) != 1 || class.Courses[0] != course {
+		t.Errorf("class terms = %+v", class)
+	}
+	if got := byKind["course"].Page; got != "all.html#"+rowAnchor(sanitizeName(course)+".ics") {
+		t.Errorf("course page = %q", got)
+	}
+	if _, ok := byKind["lecturer"]; !ok {
+		t.Error("no lecturer entry")
+	}
+}
+
+func TestRowAnchor(t *testing.T) {
+	if got := rowAnchor("DBWINFO-A04/ibl-3.ics"); got != "cal-DBWINFO-A04-ibl-3" {
+		t.Errorf("rowAnchor = %q", got)
+	}
+}
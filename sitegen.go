@@ -2,13 +2,16 @@ package main
 
 import (
 	"fmt"
-	"html"
 	"io"
+	"io/fs"
+	"log"
 	"os"
 	"path/filepath"
-	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -20,154 +23,265 @@ var (
 // Computed at runtime from publicDir
 var publicICSDir = ""
 
-type fileGroup map[string]map[string][]string // block -> subgroup -> files
+// Freshness of the published data, computed at runtime from the state file.
+var siteRun struct {
+	generated  time.Time
+	staleAfter time.Duration
+	calendars  map[string]calendarFreshness // by calendar file
 
-var (
-	// Aggregated class calendars:
-	// - letter classes: DBWINFO-A04.ics, DBBWL-B03.ics ...
-	// - numeric classes: DBING-01.ics, DBMAB-03.ics, DBWI-05.ics ...
-	aggClassRe = regexp.MustCompile(`^DB[A-Z]+-(?:[A-Z]\d{2,3}|\d{2})\.ics$`)
-
-	// Aggregated program-level calendar like DBING.ics (if generated)
-	aggBlockRe = regexp.MustCompile(`^DB[A-Z]+\.ics$`)
-
-	// Block / program prefix from filenames
-	blockRe = regexp.MustCompile(`^(DB[A-Z]+)`)
-
-	// Class subgroup matcher for BOTH styles
-	// Captures:
-	//  1) block/program (DBBWL, DBWINFO, DBING, DBMAB, DBWI, ...)
-	//  2) class token (A04, B03, 01, 03, 05, ...)
-	classRe = regexp.MustCompile(`^(DB[A-Z]+)-((?:[A-Z]\d{2,3})|(?:\d{2}))`)
-)
+	changes     map[string][]scheduleChange // by calendar file without .ics, newest first
+	changeKeep  time.Duration
+	changeLimit int
+
+	badges map[string]string // current and next block by calendar file, see blockBadges
+}
+
+type fileGroup map[string]map[string][]calendarEntry // block -> subgroup -> calendars
 
-func generateSite() error {
+func generateSite(data scheduleData) error {
 	// Compute published ICS dir from configurable public root
 	publicICSDir = filepath.Join(publicDir, "ics_files")
 
-	if err := os.MkdirAll(publicICSDir, 0755); err != nil {
+	// Fail before writing anything if the languages or a custom theme are broken
+	locs, err := siteLocales()
+	if err != nil {
 		return err
 	}
+	if _, err := currentTheme(); err != nil {
+		return err
+	}
+	maxAge, err := parseLeadTime(staleAfter)
+	if err != nil {
+		return fmt.Errorf("ASW_STALE_AFTER: %w", err)
+	}
+	keep, err := parseLeadTime(changelogRetention)
+	if err != nil {
+		return fmt.Errorf("ASW_CHANGELOG_RETENTION: %w", err)
+	}
+	limit, err := strconv.Atoi(changelogLimit)
+	if err != nil || limit < 1 {
+		return fmt.Errorf("ASW_CHANGELOG_LIMIT: %q is not a positive number", changelogLimit)
+	}
 
-	// Copy ICS files to public folder for GitHub Pages
-	icsFiles, err := filepath.Glob(filepath.Join(outputDir, "*.ics"))
+	// Compare with the previous runs
+	st, err := loadState(stateFile)
 	if err != nil {
+		log.Printf("warning: ignoring state file: %v", err)
+		st = newRunState()
+	}
+	now := time.Now()
+	cals := publishedCalendars(data)
+	st.update(data, cals, now)
+	st.recordChanges(data, cals, now, keep)
+	siteRun.generated = now
+	siteRun.staleAfter = maxAge
+	siteRun.calendars = buildFreshness(st, data, cals, now, maxAge)
+	siteRun.changes, siteRun.changeKeep, siteRun.changeLimit = st.Changes, keep, limit
+	siteRun.badges = blockBadges(data, now)
+	stale := st.staleSources(now, maxAge)
+	for _, course := range stale {
+		log.Printf("warning: %s not fetched successfully for more than %s", course, staleAfter)
+	}
+
+	if err := os.MkdirAll(publicICSDir, 0755); err != nil {
 		return err
 	}
 
-	for _, src := range icsFiles {
-		dst := filepath.Join(publicICSDir, filepath.Base(src))
-		if err := copyFile(src, dst); err != nil {
-			return err
+	// Copy generated files (including subfolders like reminders/) to public folder for GitHub Pages
+	if err := copyTree(outputDir, publicICSDir); err != nil {
+		return err
+	}
+
+	// JSON dataset next to the published .ics files
+	if err := writeJSONExport(publicICSDir, data, now); err != nil {
+		return fmt.Errorf("json export: %w", err)
+	}
+
+	if err := os.MkdirAll(publicDir, 0755); err != nil {
+		return err
+	}
+
+	// Spreadsheet-friendly CSV next to each calendar
+	if err := writeCSVExport(publicICSDir, data); err != nil {
+		return fmt.Errorf("csv export: %w", err)
+	}
+
+	// QR codes of the subscription links, for the rows and the handouts
+	if err := writeQRCodes(); err != nil {
+		return fmt.Errorf("qr codes: %w", err)
+	}
+
+	// Calendar list for apps and bots
+	if err := writeManifest(data, cals, now); err != nil {
+		return fmt.Errorf("manifest: %w", err)
+	}
+
+	// Prebuilt index for the search box
+	if err := writeSearchIndex(data); err != nil {
+		return fmt.Errorf("search index: %w", err)
+	}
+
+	// One copy of the pages per language
+	for _, l := range locs {
+		if err := renderLanguage(l, data, cals); err != nil {
+			return fmt.Errorf("%s pages: %w", l.Lang, err)
 		}
 	}
 
-	// Collect names from public dir (the actual published set)
-	pubFiles, err := filepath.Glob(filepath.Join(publicICSDir, "*.ics"))
-	if err != nil {
+	// Room occupancy report and free-room finder
+	if err := renderOccupancy(data, locs, now); err != nil {
+		return err
+	}
+
+	// Contact-hour statistics
+	if err := renderStats(data, locs); err != nil {
+		return err
+	}
+
+	// Run report and conflict list
+	if _, err := writeRunReport(data, locs, stale, now); err != nil {
 		return err
 	}
 
-	names := make([]string, 0, len(pubFiles))
-	for _, p := range pubFiles {
-		names = append(names, filepath.Base(p))
+	// Old links to pages in the public root
+	if err := writeLanguageRedirects(locs); err != nil {
+		return err
 	}
-	sort.Strings(names)
 
-	aggregated, _ := splitAggregated(names)
+	return saveState(stateFile, st)
+}
 
-	blocksAgg := groupFiles(aggregated)
-	blocksAll := groupFiles(names)
+// Write the calendar listings, timetables, preview and help pages of one
+// language.
+func renderLanguage(l locale, data scheduleData, cals []calendarEntry) error {
+	blocksAgg := groupCalendars(data, cals, "class", "current")
+	blocksExams := groupCalendars(data, cals, "exams")
+	blocksAll := groupCalendars(data, cals, "class", "current", "course", "exams")
 
-	blockOrderAgg := sortedKeys(blocksAgg)
-	blockOrderAll := sortedKeys(blocksAll)
+	// Per-module calendars, listed below their class calendar
+	modules := map[string][]moduleCalendar{}
+	for classKey, evs := range data.Classes {
+		modules[sanitizeName(classKey)+".ics"] = moduleGroups(evs)
+	}
 
-	if err := os.MkdirAll(publicDir, 0755); err != nil {
+	// Calendar preview, reads the JSON export
+	if err := renderPreview(l); err != nil {
+		return err
+	}
+
+	// Printable weekly timetables per class
+	if err := renderTimetables(data, l); err != nil {
+		return err
+	}
+
+	// Printable QR code handouts per class
+	if err := renderHandouts(data, l); err != nil {
+		return err
+	}
+
+	// Detected schedule changes per class and course calendar
+	if err := renderChangelogs(data, l); err != nil {
 		return err
 	}
 
 	// Aggregated index page
-	if err := renderPage(
-		filepath.Join(publicDir, "index.html"),
-		"ASW Class Calendars",
-		"Aggregated calendars per class/block. Recommended for subscription.",
-		blocksAgg,
-		blockOrderAgg,
-		true,
-		true,
-		false,
-	); err != nil {
+	index := l.newPage("index.html", l.T("page.index"), l.T("page.index.sub"))
+	index.Nav = siteNav(l,
+		navLink{"all.html", l.T("nav.individual"), false},
+		navLink{"exams.html", l.T("nav.exams"), false},
+	)
+	if err := renderCalendarsPage(index, blocksAgg, modules); err != nil {
+		return err
+	}
+
+	// Exam-only calendars
+	exams := l.newPage("exams.html", l.T("page.exams"), l.T("page.exams.sub"))
+	exams.Nav = siteNav(l, navLink{"index.html", l.T("nav.index"), false})
+	if err := renderCalendarsPage(exams, blocksExams, nil); err != nil {
 		return err
 	}
 
 	// Full listing page
-	if err := renderPage(
-		filepath.Join(publicDir, "all.html"),
-		"ASW All Calendars",
-		"All generated calendars including individual block files.",
-		blocksAll,
-		blockOrderAll,
-		true,
-		false,
-		true,
-	); err != nil {
+	all := l.newPage("all.html", l.T("page.all"), l.T("page.all.sub"))
+	all.Nav = siteNav(l,
+		navLink{"index.html", l.T("nav.index"), false},
+		navLink{"exams.html", l.T("nav.exams"), false},
+	)
+	if err := renderCalendarsPage(all, blocksAll, modules); err != nil {
 		return err
 	}
 
-	// Help page for Google/Android
-	if err := renderGoogleHelpPage(
-		filepath.Join(publicDir, "help-google.html"),
+	// Room and lecturer timetables
+	if err := renderResourcePage(l, "rooms.html",
+		l.T("page.rooms"),
+		l.T("page.rooms.sub"),
+		roomCalendars(data),
 	); err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func splitAggregated(names []string) (aggregated []string, individual []string) {
-	for _, name := range names {
-		if aggClassRe.MatchString(name) || aggBlockRe.MatchString(name) {
-			aggregated = append(aggregated, name)
-		} else {
-			individual = append(individual, name)
-		}
+	if err := renderResourcePage(l, "lecturers.html",
+		l.T("page.lecturers"),
+		l.T("page.lecturers.sub"),
+		lecturerCalendars(data),
+	); err != nil {
+		return err
 	}
-	return
+
+	// Help page for Google/Android
+	return renderGoogleHelpPage(l)
 }
 
-func groupFiles(fileList []string) fileGroup {
+// Published class, course or exam calendars of the given kinds by program
+// and class, from the course metadata. Calendars of unrecognized course
+// names end up in the otherClass block.
+func groupCalendars(data scheduleData, cals []calendarEntry, kinds ...string) fileGroup {
 	blocks := make(fileGroup)
 
-	for _, name := range fileList {
-		n := normName(name)
-
-		bm := blockRe.FindStringSubmatch(n)
-		block := "Other"
-		if len(bm) == 2 {
-			block = bm[1]
+	for _, c := range cals {
+		if !slices.Contains(kinds, c.Kind) || !hasPublishedFile(c.File+".ics") {
+			continue
 		}
 
-		cm := classRe.FindStringSubmatch(n)
-		cls := ""
-		if len(cm) == 3 {
-			cls = cm[2]
+		meta := data.class(c.Class)
+		block := meta.Program
+		if block == "" {
+			block = otherClass
+		}
+		cls := meta.Class
+		if cls == "" {
+			cls = "__items__"
 		}
 
 		if _, ok := blocks[block]; !ok {
-			blocks[block] = map[string][]string{}
+			blocks[block] = map[string][]calendarEntry{}
 		}
+		blocks[block][cls] = append(blocks[block][cls], c)
+	}
 
-		if cls != "" {
-			blocks[block][cls] = append(blocks[block][cls], name)
-		} else {
-			blocks[block]["__items__"] = append(blocks[block]["__items__"], name)
+	// Class calendars first, then the blocks in chronological order
+	rank := map[string]int{"class": 0, "current": 1, "exams": 2, "course": 3}
+	blockOrder := map[string]int{}
+	for classKey := range data.Classes {
+		for i, name := range data.classBlocks(classKey) {
+			blockOrder[name] = i
 		}
 	}
-
-	// Sort inner slices
 	for _, sub := range blocks {
-		for k := range sub {
-			sort.Strings(sub[k])
+		for _, items := range sub {
+			sort.SliceStable(items, func(i, j int) bool {
+				a, b := items[i], items[j]
+				if a.Class != b.Class {
+					return a.Class < b.Class
+				}
+				if a.Kind != b.Kind {
+					return rank[a.Kind] < rank[b.Kind]
+				}
+				if a.Kind == "course" && blockOrder[a.Name] != blockOrder[b.Name] {
+					return blockOrder[a.Name] < blockOrder[b.Name]
+				}
+				return a.File < b.File
+			})
 		}
 	}
 
@@ -193,457 +307,234 @@ func subgroupOrder(keys []string) []string {
 	return classes
 }
 
+// Blocks in name order, unrecognized names last.
 func sortedKeys(m fileGroup) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
-		keys = append(keys, k)
+		if k != otherClass {
+			keys = append(keys, k)
+		}
 	}
 	sort.Strings(keys)
+	if _, ok := m[otherClass]; ok {
+		keys = append(keys, otherClass)
+	}
 	return keys
 }
 
-func normName(name string) string {
-	n := name
-	n = strings.ReplaceAll(n, "_", "-")
-	n = strings.ReplaceAll(n, " ", "-")
-	n = strings.TrimSuffix(n, ".ics")
-	n = regexp.MustCompile(`-{2,}`).ReplaceAllString(n, "-")
-	return n
-}
-
 func niceLabel(fname string) string {
 	base := strings.TrimSuffix(fname, ".ics")
 	return strings.ReplaceAll(base, "_", " ")
 }
 
-func renderPage(path, title, subtitle string, blocks fileGroup, blockOrder []string, showToolbar bool, navToAll bool, navToIndex bool) error {
-	var b strings.Builder
-
-	b.WriteString("<!doctype html><html><head><meta charset='utf-8'>")
-	b.WriteString("<meta name='viewport' content='width=device-width, initial-scale=1'>")
-	b.WriteString("<title>" + html.EscapeString(title) + "</title>")
-	b.WriteString("<style>" + siteCSS() + "</style>")
-	b.WriteString("</head><body>")
-
-	b.WriteString("<header>")
-	b.WriteString("<h1>" + html.EscapeString(title) + "</h1>")
-	b.WriteString("<p>" + html.EscapeString(subtitle) + "</p>")
-	b.WriteString("</header>")
-
-	b.WriteString("<div class='navline'>")
-	if navToAll {
-		b.WriteString("<a class='navlink' href='all.html'>Show individual calendars</a>")
+type toolbarItem struct {
+	Name  string
+	Label string
+	Count int
+}
+
+type blockView struct {
+	Name      string // program, or otherClass for unrecognized course names
+	Label     string
+	Count     int
+	Subgroups []subgroupView
+}
+
+type subgroupView struct {
+	Class string // empty for files without a class
+	Items []calendarRow
+}
+
+// Data of the calendar listing pages (pages/calendars.html).
+type calendarsPage struct {
+	pageData
+	Toolbar []toolbarItem
+	Blocks  []blockView
+}
+
+// One calendar with its actions; links are empty when not published.
+type calendarRow struct {
+	locale
+	Name      string
+	Label     string
+	Anchor    string
+	Reminders bool
+	Preview   string
+	Timetable string
+	Handout   string
+	Changes   string
+	CSV       string
+	QR        string // qr/ path without the .https.svg/.webcal.svg suffix
+	Events    int
+	Range     string // first to last event
+	Changed   string // last content change
+	Stale     string // warning when a source was not fetched recently
+	Badge     string // current or next block
+	Modules   []calendarRow
+}
+
+func newCalendarRow(l locale, name, label string) calendarRow {
+	base := strings.TrimSuffix(name, ".ics")
+	r := calendarRow{locale: l, Name: name, Label: label, Anchor: rowAnchor(name), Reminders: hasReminderVariant(name)}
+	if hasPublishedFile(base + ".json") {
+		r.Preview = previewLink(name)
 	}
-	if navToIndex {
-		b.WriteString("<a class='navlink' href='index.html'>Back to class calendars</a>")
+	if tt := "timetable-" + base + ".html"; hasPublishedPage(l, tt) {
+		r.Timetable = tt
 	}
-	b.WriteString("<a class='navlink secondary' href='" + html.EscapeString(sourcePage) + "'>Source page</a>")
-	b.WriteString("</div>")
-
-	if showToolbar && len(blockOrder) > 0 {
-		b.WriteString("<div class='toolbar'>")
-		for _, block := range blockOrder {
-			count := 0
-			for _, files := range blocks[block] {
-				count += len(files)
-			}
-			safeBlock := html.EscapeString(block)
-			b.WriteString("<a class='toolbtn' href='#" + safeBlock + "'>")
-			b.WriteString("<span>" + safeBlock + "</span>")
-			b.WriteString("<span class='count'>" + strconvI(count) + "</span>")
-			b.WriteString("</a>")
+	if ho := "handout-" + base + ".html"; hasPublishedPage(l, ho) {
+		r.Handout = ho
+	}
+	if ch := changelogFile(base); hasPublishedPage(l, ch) {
+		r.Changes = ch
+	}
+	if hasPublishedFile(base + ".csv") {
+		r.CSV = base + ".csv"
+	}
+	if hasPublishedQR(name) {
+		r.QR = base
+	}
+	if b, ok := siteRun.badges[name]; ok {
+		r.Badge = l.T("row." + b)
+	}
+	if f, ok := siteRun.calendars[name]; ok {
+		r.Events = f.Events
+		if first, last := f.First.Format(dateFormat), f.Last.Format(dateFormat); f.Events > 0 && first == last {
+			r.Range = first
+		} else if f.Events > 0 {
+			r.Range = first + " – " + last
+		}
+		if !f.Changed.IsZero() {
+			r.Changed = displayTime(f.Changed)
 		}
-		b.WriteString("</div>")
-	}
-
-	// Platform hint box
-	b.WriteString("<div class='infobox'><div>")
-	b.WriteString("<div class='infobox-title'>Quick setup</div>")
-	b.WriteString("<div class='infobox-body'>")
-	b.WriteString("Use <b>Subscribe</b> for webcal subscription (best supported on Apple). ")
-	b.WriteString("For Google Calendar on Android/Windows, ")
-	b.WriteString("<a href='help-google.html'>follow this guide</a>. ")
-	b.WriteString("Alternatively use <b>Copy URL</b> to add the feed manually or <b>Download file</b> for a one-time import.")
-	b.WriteString("</div>")
-	b.WriteString("</div></div>")
-
-	b.WriteString("<main>")
-
-	totalFiles := 0
-	for _, block := range blocks {
-		for _, files := range block {
-			totalFiles += len(files)
+		if f.StaleSince != nil {
+			if f.StaleSince.IsZero() {
+				r.Stale = l.T("row.staleNever")
+			} else {
+				r.Stale = l.T("row.stale", displayTime(*f.StaleSince))
+			}
 		}
 	}
+	return r
+}
 
-	if totalFiles == 0 {
-		b.WriteString("<section class='group'><h2>No files</h2>")
-		b.WriteString("<p class='small'>No ICS files were generated yet.</p></section>")
-	} else {
-		for _, block := range blockOrder {
-			blockDict := blocks[block]
-			blockTotal := 0
-			for _, files := range blockDict {
-				blockTotal += len(files)
-			}
+// Calendar listing with the blocks in display order and a toolbar jumping
+// to them; pd carries the title and navigation. modules maps a class
+// calendar file to its per-module calendars; may be nil.
+func renderCalendarsPage(pd pageData, blocks fileGroup, modules map[string][]moduleCalendar) error {
+	l := pd.locale
+	page := calendarsPage{pageData: pd}
 
-			safeBlock := html.EscapeString(block)
-			b.WriteString("<section class='group' id='" + safeBlock + "'>")
-			b.WriteString("<h2>" + safeBlock + " <span class='badge'>" + strconvI(blockTotal) + " files</span></h2>")
+	for _, block := range sortedKeys(blocks) {
+		blockDict := blocks[block]
+		view := blockView{Name: block, Label: block}
+		if block == otherClass {
+			view.Label = l.T("cal.other")
+		}
 
-			keys := make([]string, 0, len(blockDict))
-			for k := range blockDict {
-				keys = append(keys, k)
+		keys := make([]string, 0, len(blockDict))
+		for k := range blockDict {
+			keys = append(keys, k)
+		}
+		for _, k := range subgroupOrder(keys) {
+			items := blockDict[k]
+			if len(items) == 0 {
+				continue
 			}
-			keys = subgroupOrder(keys)
-
-			for _, k := range keys {
-				items := blockDict[k]
-				if len(items) == 0 {
-					continue
-				}
+			view.Count += len(items)
 
-				b.WriteString("<div class='subgroup'>")
-
-				if k == "__items__" {
-					b.WriteString("<div class='subhead'>General <span class='subbadge'>" + strconvI(len(items)) + "</span></div>")
-				} else {
-					b.WriteString("<div class='subhead'>Class " + html.EscapeString(k) + " <span class='subbadge'>" + strconvI(len(items)) + "</span></div>")
+			sub := subgroupView{}
+			if k != "__items__" {
+				sub.Class = k
+			}
+			for _, c := range items {
+				name := c.File + ".ics"
+				label := niceLabel(name)
+				if c.Kind == "current" {
+					label = l.T("cal.current", c.Class)
 				}
-
-				b.WriteString("<ul>")
-				for _, name := range items {
-					label := niceLabel(name)
-					safeName := html.EscapeString(name)
-					safeLabel := html.EscapeString(label)
-
-					b.WriteString("<li>")
-					b.WriteString("<div class='row'>")
-					b.WriteString("<div class='row-left'>")
-					b.WriteString("<div class='file'>" + safeLabel + "</div>")
-					b.WriteString("<div class='small'>" + safeName + "</div>")
-					b.WriteString("</div>")
-					b.WriteString("<div class='actions'>")
-					b.WriteString("<button class='btn btn-primary' onclick=\"subscribe('" + safeName + "')\">Subscribe</button>")
-					b.WriteString("<button class='btn' onclick=\"copyUrl('" + safeName + "', this)\">Copy URL</button>")
-					b.WriteString("<a class='btn' href='ics_files/" + safeName + "'>Download file</a>")
-					b.WriteString("</div>")
-					b.WriteString("</div>")
-					b.WriteString("</li>")
+				row := newCalendarRow(l, name, label)
+				for _, m := range modules[name] {
+					modName := moduleFile(strings.TrimSuffix(name, ".ics"), m.Slug) + ".ics"
+					row.Modules = append(row.Modules, newCalendarRow(l, modName, m.Name))
 				}
-				b.WriteString("</ul>")
-				b.WriteString("</div>")
+				sub.Items = append(sub.Items, row)
 			}
+			view.Subgroups = append(view.Subgroups, sub)
+		}
 
-			b.WriteString("</section>")
+		if view.Count == 0 {
+			continue
 		}
+		page.Blocks = append(page.Blocks, view)
+		page.Toolbar = append(page.Toolbar, toolbarItem{block, view.Label, view.Count})
 	}
 
-	b.WriteString("</main>")
-	b.WriteString("<footer>Updated by GitHub Actions on schedule.</footer>")
-	b.WriteString(siteJS())
-	b.WriteString("</body></html>")
-
-	return os.WriteFile(path, []byte(b.String()), 0644)
-}
-
-func renderGoogleHelpPage(path string) error {
-	var b strings.Builder
-
-	title := "Google Calendar setup"
-	subtitle := "How to add these ASW calendars on Android and Google Calendar."
-
-	b.WriteString("<!doctype html><html><head><meta charset='utf-8'>")
-	b.WriteString("<meta name='viewport' content='width=device-width, initial-scale=1'>")
-	b.WriteString("<title>" + html.EscapeString(title) + "</title>")
-	b.WriteString("<style>" + siteCSS() + "</style>")
-	b.WriteString("</head><body>")
-
-	b.WriteString("<header>")
-	b.WriteString("<h1>" + html.EscapeString(title) + "</h1>")
-	b.WriteString("<p>" + html.EscapeString(subtitle) + "</p>")
-	b.WriteString("</header>")
-
-	b.WriteString("<div class='navline'>")
-	b.WriteString("<a class='navlink' href='index.html'>Back to class calendars</a>")
-	b.WriteString("<a class='navlink secondary' href='all.html'>All calendars</a>")
-	b.WriteString("<a class='navlink secondary' href='" + html.EscapeString(sourcePage) + "'>Source page</a>")
-	b.WriteString("</div>")
-
-	b.WriteString("<main>")
-
-	b.WriteString("<section class='group'>")
-	b.WriteString("<h2>Option 1: Subscribe by URL (recommended)</h2>")
-	b.WriteString("<div class='small'>Best for automatic updates.</div>")
-	b.WriteString("<div class='subgroup'>")
-	b.WriteString("<div class='subhead'>Steps</div>")
-	b.WriteString("<ul>")
-	b.WriteString("<li><div class='row'><div class='row-left'>")
-	b.WriteString("<div class='file'>Open <a href=\"https://calendar.google.com\" target=\"_blank\" rel=\"noopener noreferrer\">Google Calendar</a> on the web</div>")
-	b.WriteString("<div class='small'>Use a browser on Android or desktop.</div>")
-	b.WriteString("</div></div></li>")
-	b.WriteString("<li><div class='row'><div class='row-left'>")
-	b.WriteString("<div class='file'>Go to “Other calendars” → “From URL”</div>")
-	b.WriteString("<div class='small'>This menu is not reliably available in the Android app.</div>")
-	b.WriteString("</div></div></li>")
-	b.WriteString("<li><div class='row'><div class='row-left'>")
-	b.WriteString("<div class='file'>Copy the HTTPS link from this site</div>")
-	b.WriteString("<div class='small'>Use the “Copy URL” button next to your class calendar.</div>")
-	b.WriteString("</div></div></li>")
-	b.WriteString("<li><div class='row'><div class='row-left'>")
-	b.WriteString("<div class='file'>Paste the link and confirm</div>")
-	b.WriteString("<div class='small'>The calendar should appear and update automatically.</div>")
-	b.WriteString("</div></div></li>")
-	b.WriteString("</ul>")
-	b.WriteString("</div>")
-	b.WriteString("</section>")
-
-	b.WriteString("<section class='group'>")
-	b.WriteString("<h2>Option 2: Import the file</h2>")
-	b.WriteString("<div class='small'>Good for one-time import, not ideal for updates.</div>")
-	b.WriteString("<div class='subgroup'>")
-	b.WriteString("<div class='subhead'>Steps</div>")
-	b.WriteString("<ul>")
-	b.WriteString("<li><div class='row'><div class='row-left'>")
-	b.WriteString("<div class='file'>Tap “Download file”</div>")
-	b.WriteString("<div class='small'>Download the .ics file to your device.</div>")
-	b.WriteString("</div></div></li>")
-	b.WriteString("<li><div class='row'><div class='row-left'>")
-	b.WriteString("<div class='file'>Open it with your calendar app</div>")
-	b.WriteString("<div class='small'>Depending on vendor apps, the import dialog appears automatically.</div>")
-	b.WriteString("</div></div></li>")
-	b.WriteString("</ul>")
-	b.WriteString("</div>")
-	b.WriteString("</section>")
-
-	b.WriteString("<section class='group'>")
-	b.WriteString("<h2>Note</h2>")
-	b.WriteString("<div class='small'>")
-	b.WriteString("The “Subscribe” button uses the webcal protocol which is best supported on Apple devices. ")
-	b.WriteString("On many Android setups, the safest path is using the HTTPS link or importing the file.")
-	b.WriteString("</div>")
-	b.WriteString("</section>")
-
-	b.WriteString("</main>")
-	b.WriteString("<footer>Updated by GitHub Actions on schedule.</footer>")
-	b.WriteString("</body></html>")
-
-	return os.WriteFile(path, []byte(b.String()), 0644)
-}
-
-func siteCSS() string {
-	return `
-:root{
-  --bg:#0f1115; --card:#161a22; --text:#e6e6e6; --muted:#a7b0c0;
-  --accent:#7aa2ff; --border:#262c3a; --accent-weak: rgba(122,162,255,.12);
-  --success: rgba(120, 255, 170, .12);
-}
-*{box-sizing:border-box}
-body{
-  margin:0; font-family: system-ui, -apple-system, Segoe UI, Roboto, Arial, sans-serif;
-  background:linear-gradient(180deg, #0f1115, #0b0d12);
-  color:var(--text);
-}
-header{
-  padding:40px 20px 8px; text-align:center;
-}
-header h1{margin:0 0 6px; font-size:28px; letter-spacing:.3px}
-header p{margin:0; color:var(--muted)}
-
-.navline{
-  max-width:1000px; margin:10px auto 0; padding:0 20px 10px;
-  display:flex; gap:10px; justify-content:center; flex-wrap:wrap;
-}
-.navlink{
-  display:inline-flex; align-items:center; gap:8px;
-  padding:8px 12px; border-radius:10px;
-  background:var(--accent-weak); color:var(--text);
-  border:1px solid rgba(122,162,255,.35);
-  text-decoration:none; font-size:12px; font-weight:600;
-}
-.navlink:hover{filter:brightness(1.08)}
-.navlink.secondary{
-  background:rgba(255,255,255,.04);
-  border-color: var(--border);
-  color: var(--muted);
-}
-
-/* Info box */
-.infobox{
-  max-width:1000px;
-  margin: 6px auto 0;
-  padding: 0 20px;
-}
-.infobox > div{
-  background: rgba(255,255,255,.04);
-  border: 1px solid var(--border);
-  border-radius: 12px;
-  padding: 12px 14px;
-}
-.infobox-title{
-  font-size: 12px;
-  font-weight: 700;
-  letter-spacing: .2px;
-  margin-bottom: 4px;
-}
-.infobox-body{
-  font-size: 11.5px;
-  color: var(--muted);
-}
-.infobox-body a{
-  color: var(--accent);
-  text-decoration: none;
-  font-weight: 600;
-}
-.infobox-body a:hover{
-  text-decoration: underline;
+	return renderThemePage("calendars.html", page)
 }
 
-.toolbar{
-  max-width:1000px; margin:12px auto 0; padding:0 20px 8px;
-  display:flex; gap:8px; flex-wrap:wrap; justify-content:center;
-}
-.toolbtn{
-  display:inline-flex; align-items:center; gap:8px;
-  padding:8px 12px; border-radius:10px;
-  background:var(--accent-weak); color:var(--text);
-  border:1px solid rgba(122,162,255,.35);
-  text-decoration:none; font-size:12px; font-weight:600;
-}
-.toolbtn:hover{filter:brightness(1.08)}
-.toolbtn .count{
-  font-size:10px; padding:1px 6px; border-radius:999px;
-  background:rgba(255,255,255,.06); border:1px solid var(--border);
-  color:var(--muted);
+// Data of the room and lecturer index pages (pages/resources.html).
+type resourcesPage struct {
+	pageData
+	Items []calendarRow
 }
 
-main{
-  max-width:1000px; margin:0 auto; padding:18px 20px 10px;
-  display:grid; gap:16px;
+// Index page for room or lecturer timetables.
+func renderResourcePage(l locale, file, title, subtitle string, items []resourceCalendar) error {
+	page := resourcesPage{pageData: l.newPage(file, title, subtitle)}
+	for _, rc := range items {
+		page.Items = append(page.Items, newCalendarRow(l, rc.File+".ics", rc.Label))
+	}
+	return renderThemePage("resources.html", page)
 }
 
-.group{
-  background:var(--card); border:1px solid var(--border);
-  border-radius:14px; padding:18px 18px 8px;
-  box-shadow: 0 6px 18px rgba(0,0,0,.25);
-}
-.group h2{
-  margin:0 0 12px; font-size:18px;
-  display:flex; align-items:center; gap:8px;
-}
-.badge{
-  font-size:11px; padding:2px 8px; border-radius:999px;
-  background:var(--accent-weak); color:var(--accent);
-  border:1px solid rgba(122,162,255,.35);
+func renderGoogleHelpPage(l locale) error {
+	pd := l.newPage("help-google.html", l.T("page.help"), l.T("page.help.sub"))
+	pd.Search = false
+	pd.Nav = []navLink{
+		{"index.html", l.T("nav.index"), false},
+		{"all.html", l.T("nav.all"), true},
+		{sourcePage, l.T("nav.source"), true},
+	}
+	return renderThemePage("help-google.html", pd)
 }
 
-.subgroup{
-  border-top:1px solid var(--border);
-  padding-top:12px; margin-top:12px;
-}
-.subgroup:first-of-type{
-  border-top:none; padding-top:0; margin-top:0;
-}
-.subhead{
-  display:flex; align-items:center; gap:8px;
-  margin:0 0 8px; font-size:14px; color:var(--muted);
-}
-.subbadge{
-  font-size:10px; padding:1px 7px; border-radius:999px;
-  background:rgba(255,255,255,.06); border:1px solid var(--border);
-}
+// Copy all regular files below src into dst, keeping the folder structure.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
 
-ul{list-style:none; padding:0; margin:0}
-li{border-top:1px dashed var(--border)}
-li:first-child{border-top:none}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
 
-.row{
-  display:flex; gap:10px; align-items:center; justify-content:space-between;
-  padding:10px 6px;
-}
-.row-left{
-  min-width:0; display:flex; flex-direction:column; gap:2px;
-}
-.file{
-  font-weight:600; white-space:nowrap; overflow:hidden; text-overflow:ellipsis;
-  max-width:560px;
-}
-.file a{
-  color: var(--accent);
-  text-decoration: none;
-  font-weight: 700;
-}
-.file a:hover{
-  text-decoration: underline;
-}
-.small{
-  color:var(--muted); font-size:11px;
-}
-.actions{
-  display:flex; gap:6px; flex-wrap:wrap;
-}
-.btn{
-  appearance:none; border:1px solid var(--border); background:rgba(255,255,255,.03);
-  color:var(--text); padding:6px 9px; font-size:11px; border-radius:8px;
-  cursor:pointer; text-decoration:none; font-weight:600;
-}
-.btn:hover{filter:brightness(1.08)}
-.btn-primary{
-  border-color: rgba(122,162,255,.45);
-  background: var(--accent-weak);
-}
-.btn-success{
-  border-color: rgba(120,255,170,.35);
-  background: var(--success);
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(path, target)
+	})
 }
 
-.note{
-  max-width:1000px; margin:0 auto; padding:0 20px 10px;
-  color:var(--muted); font-size:11px; text-align:center;
+// Report whether a "with reminders" variant was published for a calendar.
+func hasReminderVariant(name string) bool {
+	_, err := os.Stat(filepath.Join(publicICSDir, "reminders", name))
+	return err == nil
 }
 
-footer{
-  max-width:1000px; margin:10px auto 40px; padding:0 20px;
-  color:var(--muted); font-size:12px; text-align:center;
-}
-`
+// Report whether a page was written to the language folder of l.
+func hasPublishedPage(l locale, name string) bool {
+	_, err := os.Stat(l.path(name))
+	return err == nil
 }
 
-func siteJS() string {
-	return `
-<script>
-function fileUrl(name){
-  return new URL('ics_files/' + name, window.location.href).href;
-}
-function webcalUrl(httpsUrl){
-  return httpsUrl.replace(/^https?:\/\//i, 'webcal://');
-}
-async function copyUrl(name, btn){
-  const url = fileUrl(name);
-  try{
-    await navigator.clipboard.writeText(url);
-    flash(btn, 'Copied', true);
-  }catch(e){
-    window.prompt('Copy this URL:', url);
-  }
-}
-function subscribe(name){
-  const url = fileUrl(name);
-  const w = webcalUrl(url);
-  window.location.href = w;
-}
-function flash(btn, text, ok){
-  if(!btn) return;
-  const old = btn.textContent;
-  btn.textContent = text;
-  if(ok){ btn.classList.add('btn-success'); }
-  setTimeout(() => {
-    btn.textContent = old;
-    btn.classList.remove('btn-success');
-  }, 900);
-}
-</script>
-`
+// Report whether a file was published in the ICS folder.
+func hasPublishedFile(name string) bool {
+	_, err := os.Stat(filepath.Join(publicICSDir, name))
+	return err == nil
 }
 
 func copyFile(src, dst string) error {
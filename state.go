@@ -0,0 +1,231 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// State kept between runs (ASW_STATE_FILE): when each source schedule was
+// last fetched successfully, when the content of each calendar last
+// changed, and the event snapshots and change history of the changelog
+// pages. The output folders are rebuilt on every run, so this is the only
+// memory of earlier runs; the workflow keeps the file in the Actions cache.
+
+var (
+	stateFile  = getenv("ASW_STATE_FILE", ".asw-state.json")
+	staleAfter = getenv("ASW_STALE_AFTER", "2d")
+)
+
+type runState struct {
+	Updated   time.Time                 `json:"updated"`
+	Sources   map[string]*sourceState   `json:"sources"`   // by course name
+	Calendars map[string]*calendarState `json:"calendars"` // by calendar file, without .ics
+
+	// Class and course calendars only, by calendar file (see changelog.go)
+	Events  map[string]map[string]eventSnapshot `json:"events"`  // by event ID
+	Changes map[string][]scheduleChange         `json:"changes"` // newest first
+}
+
+type sourceState struct {
+	LastSuccess time.Time `json:"lastSuccess"`
+	LastAttempt time.Time `json:"lastAttempt"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+type calendarState struct {
+	Hash    string    `json:"hash"`
+	Changed time.Time `json:"changed"`
+}
+
+func newRunState() *runState {
+	return &runState{
+		Sources:   map[string]*sourceState{},
+		Calendars: map[string]*calendarState{},
+		Events:    map[string]map[string]eventSnapshot{},
+		Changes:   map[string][]scheduleChange{},
+	}
+}
+
+// Read the state file; a missing file is an empty state.
+func loadState(path string) (*runState, error) {
+	st := newRunState()
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, st); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if st.Sources == nil {
+		st.Sources = map[string]*sourceState{}
+	}
+	if st.Calendars == nil {
+		st.Calendars = map[string]*calendarState{}
+	}
+	if st.Events == nil {
+		st.Events = map[string]map[string]eventSnapshot{}
+	}
+	if st.Changes == nil {
+		st.Changes = map[string][]scheduleChange{}
+	}
+	return st, nil
+}
+
+func saveState(path string, st *runState) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return writeJSONFile(path, st)
+}
+
+// Fingerprint of a calendar's content. Built from the JSON export fields, so
+// it ignores DTSTAMP and the other per-run parts of the .ics file.
+func calendarHash(events []ScheduleEvent) string {
+	b, _ := json.Marshal(toJSONEvents(events))
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:16])
+}
+
+// Record this run: fetch results per source and content changes per calendar.
+// Calendars that are no longer published are dropped.
+func (st *runState) update(data scheduleData, cals []calendarEntry, now time.Time) {
+	st.Updated = now
+
+	for course := range data.Courses {
+		st.source(course).LastSuccess = now
+		st.source(course).LastAttempt = now
+		st.source(course).LastError = ""
+	}
+	for course, msg := range data.Failed {
+		st.source(course).LastAttempt = now
+		st.source(course).LastError = msg
+	}
+	// Courses no longer linked from the overview page
+	for course, s := range st.Sources {
+		if s.LastAttempt.Before(now) {
+			delete(st.Sources, course)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, c := range cals {
+		seen[c.File] = true
+		h := calendarHash(c.Events)
+		if cs, ok := st.Calendars[c.File]; !ok || cs.Hash != h {
+			st.Calendars[c.File] = &calendarState{Hash: h, Changed: now}
+		}
+	}
+	for file := range st.Calendars {
+		if !seen[file] {
+			delete(st.Calendars, file)
+		}
+	}
+}
+
+func (st *runState) source(course string) *sourceState {
+	s, ok := st.Sources[course]
+	if !ok {
+		s = &sourceState{}
+		st.Sources[course] = s
+	}
+	return s
+}
+
+// Freshness of one calendar as shown on the site.
+type calendarFreshness struct {
+	Changed time.Time
+	Events  int
+	First   time.Time
+	Last    time.Time
+	// Oldest successful fetch among the calendar's sources, set only when
+	// that is older than ASW_STALE_AFTER (zero time: never fetched).
+	StaleSince *time.Time
+}
+
+// Freshness per calendar file (with .ics). The sources of a calendar are the
+// courses of its events; class calendars also depend on the failed courses
+// of their class, whose events are missing.
+func buildFreshness(st *runState, data scheduleData, cals []calendarEntry, now time.Time, maxAge time.Duration) map[string]calendarFreshness {
+	failedByClass := map[string][]string{}
+	for course := range data.Failed {
+		k := extractClassKey(course)
+		failedByClass[k] = append(failedByClass[k], course)
+	}
+
+	out := map[string]calendarFreshness{}
+	for _, c := range cals {
+		f := calendarFreshness{Events: len(c.Events)}
+		if cs, ok := st.Calendars[c.File]; ok {
+			f.Changed = cs.Changed
+		}
+
+		sources := map[string]bool{}
+		for _, e := range c.Events {
+			if f.First.IsZero() || e.Start.Before(f.First) {
+				f.First = e.Start
+			}
+			if e.End.After(f.Last) {
+				f.Last = e.End
+			}
+			sources[e.CourseName] = true
+		}
+		if c.Class != "" && c.Kind != "course" {
+			for _, course := range failedByClass[c.Class] {
+				sources[course] = true
+			}
+		}
+
+		for s := range sources {
+			var last time.Time
+			if src, ok := st.Sources[s]; ok {
+				last = src.LastSuccess
+			}
+			if now.Sub(last) > maxAge && (f.StaleSince == nil || last.Before(*f.StaleSince)) {
+				t := last
+				f.StaleSince = &t
+			}
+		}
+
+		out[c.File+".ics"] = f
+	}
+	return out
+}
+
+var displayLocation = sync.OnceValue(func() *time.Location {
+	loc, err := time.LoadLocation(tzID)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+})
+
+// Date and time in the schedule's timezone, as shown on the site.
+func displayTime(t time.Time) string {
+	return t.In(displayLocation()).Format("02.01.2006 15:04")
+}
+
+// Courses whose last successful fetch is older than maxAge, sorted.
+func (st *runState) staleSources(now time.Time, maxAge time.Duration) []string {
+	var out []string
+	for course, s := range st.Sources {
+		if now.Sub(s.LastSuccess) > maxAge {
+			out = append(out, course)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
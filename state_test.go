@@ -0,0 +1,72 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunStateFreshness(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 12, d, 10, 0, 0, 0, time.UTC) }
+	ev := func(course string, d int) ScheduleEvent {
+		return ScheduleEvent{CourseName: course, Summary: "IBL III", Start: day(d), End: day(d).Add(90 * time.Minute)}
+	}
+	cals := func(data scheduleData) []calendarEntry {
+		var out []calendarEntry
+		for _, k := range eventKeys(data.Classes) {
+			out = append(out, calendarEntry{"class", k, k, k, data.Classes[k]})
+		}
+		return out
+	}
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	// Run 1: both blocks fetched.
+	data := newScheduleData()
+	data.Courses["DBWINFO-A04 - 3. Block"] = []ScheduleEvent{ev("DBWINFO-A04 - 3. Block", 8)}
+	data.Courses["DBWINFO-A04 - 4. Block"] = []ScheduleEvent{ev("DBWINFO-A04 - 4. Block", 15)}
+	data.Classes["DBWINFO-A04"] = []ScheduleEvent{ev("DBWINFO-A04 - 3. Block", 8), ev("DBWINFO-A04 - 4. Block", 15)}
+	st, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.update(data, cals(data), day(1))
+	if err := saveState(path, st); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run 2: same content, so the change time stays.
+	st, err = loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.update(data, cals(data), day(2))
+	f := buildFreshness(st, data, cals(data), day(2), 48*time.Hour)["DBWINFO-A04.ics"]
+	if !f.Changed.Equal(day(1)) || f.Events != 2 || !f.First.Equal(day(8)) || f.StaleSince != nil {
+		t.Errorf("run 2: %+v", f)
+	}
+
+	// Runs 3 and 4: the 4th block fails, the class calendar loses its events.
+	data.Failed["DBWINFO-A04 - 4. Block"] = "timeout"
+	delete(data.Courses, "DBWINFO-A04 - 4. Block")
+	data.Classes["DBWINFO-A04"] = data.Classes["DBWINFO-A04"][:1]
+	st.update(data, cals(data), day(3))
+	f = buildFreshness(st, data, cals(data), day(3), 48*time.Hour)["DBWINFO-A04.ics"]
+	if !f.Changed.Equal(day(3)) || f.StaleSince != nil {
+		t.Errorf("run 3: %+v", f)
+	}
+	st.update(data, cals(data), day(5))
+	f = buildFreshness(st, data, cals(data), day(5), 48*time.Hour)["DBWINFO-A04.ics"]
+	if f.StaleSince == nil || !f.StaleSince.Equal(day(2)) {
+		t.Errorf("run 4: stale since %v, want %v", f.StaleSince, day(2))
+	}
+	if got := st.staleSources(day(5), 48*time.Hour); len(got) != 1 || got[0] != "DBWINFO-A04 - 4. Block" {
+		t.Errorf("stale sources = %v", got)
+	}
+
+	// Run 5: the block is gone from the overview page and is forgotten.
+	delete(data.Failed, "DBWINFO-A04 - 4. Block")
+	st.update(data, cals(data), day(6))
+	if _, ok := st.Sources["DBWINFO-A04 - 4. Block"]; ok {
+		t.Error("removed course still in state")
+	}
+}
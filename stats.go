@@ -0,0 +1,470 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Contact-hour statistics for study-program coordinators: hours per module,
+// event type, week and class, available as `stats` subcommand, as stats.html
+// with weekly load charts and as stats.csv.
+
+const (
+	noModule = "(no module)"
+	noType   = "(no type)"
+)
+
+// statRow is the finest aggregation level; every view on the page and in the
+// CLI is a sum over these rows.
+type statRow struct {
+	Class     string
+	Block     string // course name, e.g. "DBWINFO-A04 - 3. Block"
+	Semester  string // "WiSe 2025/26", "SoSe 2026"
+	Week      string // Monday of the calendar week, 2025-12-08
+	StudyWeek int    // sked "n. Studienwoche", 0 if unknown
+	Module    string
+	Type      string
+	Events    int
+	Hours     float64
+}
+
+var statsCSVHeader = []string{"class", "block", "semester", "week", "study_week", "module", "type", "events", "hours"}
+
+// Aggregate all non-cancelled class events into stat rows, sorted by class,
+// week, block, module and type.
+func buildStats(data scheduleData) []statRow {
+	type key struct {
+		Class, Block, Week, Module, Type string
+	}
+	byKey := map[key]*statRow{}
+
+	for _, classKey := range eventKeys(data.Classes) {
+		events := data.Classes[classKey]
+
+		// Same module grouping as the per-module calendars, so spelling
+		// variants add up to one line.
+		names := map[string]string{}
+		for _, mc := range moduleGroups(events) {
+			names[mc.Slug] = mc.Name
+		}
+
+		for _, e := range events {
+			if e.Status == StatusCancelled {
+				continue
+			}
+
+			module := noModule
+			if e.Module != "" {
+				if n, ok := names[moduleSlug(e.Module, moduleAliases)]; ok {
+					module = n
+				}
+			}
+			typ := e.EventType
+			if typ == "" {
+				typ = noType
+			}
+
+			k := key{classKey, e.CourseName, mondayOf(e.Start).Format("2006-01-02"), module, typ}
+			r, ok := byKey[k]
+			if !ok {
+				r = &statRow{
+					Class:     k.Class,
+					Block:     k.Block,
+					Semester:  semesterOf(e.Start),
+					Week:      k.Week,
+					StudyWeek: e.Week,
+					Module:    k.Module,
+					Type:      k.Type,
+				}
+				byKey[k] = r
+			}
+			r.Events++
+			r.Hours += e.End.Sub(e.Start).Hours()
+		}
+	}
+
+	rows := make([]statRow, 0, len(byKey))
+	for _, r := range byKey {
+		rows = append(rows, *r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Class != b.Class {
+			return a.Class < b.Class
+		}
+		if a.Week != b.Week {
+			return a.Week < b.Week
+		}
+		if a.Block != b.Block {
+			return a.Block < b.Block
+		}
+		if a.Module != b.Module {
+			return a.Module < b.Module
+		}
+		return a.Type < b.Type
+	})
+
+	return rows
+}
+
+// Monday 00:00 of the week containing t.
+func mondayOf(t time.Time) time.Time {
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+}
+
+// German semester of a date: summer semester April–September,
+// winter semester October–March.
+func semesterOf(t time.Time) string {
+	y := t.Year()
+	switch {
+	case t.Month() >= time.April && t.Month() <= time.September:
+		return fmt.Sprintf("SoSe %d", y)
+	case t.Month() >= time.October:
+		return fmt.Sprintf("WiSe %d/%02d", y, (y+1)%100)
+	default:
+		return fmt.Sprintf("WiSe %d/%02d", y-1, y%100)
+	}
+}
+
+// statSum is one line of an aggregated view.
+type statSum struct {
+	Key    string
+	Events int
+	Hours  float64
+}
+
+// Sum rows by the given dimension, sorted by key.
+func sumStats(rows []statRow, dim func(statRow) string) []statSum {
+	byKey := map[string]*statSum{}
+	for _, r := range rows {
+		k := dim(r)
+		s, ok := byKey[k]
+		if !ok {
+			s = &statSum{Key: k}
+			byKey[k] = s
+		}
+		s.Events += r.Events
+		s.Hours += r.Hours
+	}
+
+	out := make([]statSum, 0, len(byKey))
+	for _, s := range byKey {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// Dimensions selectable with `stats -by`.
+var statDims = map[string]func(statRow) string{
+	"class":    func(r statRow) string { return r.Class },
+	"block":    func(r statRow) string { return r.Block },
+	"semester": func(r statRow) string { return r.Class + " · " + r.Semester },
+	"module":   func(r statRow) string { return r.Class + " · " + r.Module },
+	"type":     func(r statRow) string { return r.Class + " · " + r.Type },
+	"week":     func(r statRow) string { return r.Class + " · " + r.Week },
+}
+
+func writeStatsCSV(w io.Writer, rows []statRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(statsCSVHeader); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		rec := []string{
+			r.Class, r.Block, r.Semester, r.Week, strconv.Itoa(r.StudyWeek),
+			r.Module, r.Type, strconv.Itoa(r.Events), formatHours(r.Hours),
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatHours(h float64) string {
+	return strconv.FormatFloat(h, 'f', 2, 64)
+}
+
+// `stats` subcommand: parse the schedules and print contact hours.
+// Progress logging goes to stderr, so the output can be piped.
+//
+//	asw-parser stats [-by class|block|semester|module|type|week] [-class KEY] [-csv]
+func runStatsCommand(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	by := fs.String("by", "module", "group by class, block, semester, module, type or week")
+	class := fs.String("class", "", "only this class key, e.g. DBWINFO-A04")
+	asCSV := fs.Bool("csv", false, "print the detailed rows as CSV")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	dim, ok := statDims[*by]
+	if !ok {
+		return fmt.Errorf("unknown -by %q", *by)
+	}
+
+	data, err := loadSchedule()
+	if err != nil {
+		return err
+	}
+
+	rows := buildStats(data)
+	if *class != "" {
+		var filtered []statRow
+		for _, r := range rows {
+			if r.Class == *class {
+				filtered = append(filtered, r)
+			}
+		}
+		rows = filtered
+	}
+
+	if *asCSV {
+		return writeStatsCSV(os.Stdout, rows)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tevents\thours\t\n", *by)
+	var events int
+	var hours float64
+	for _, s := range sumStats(rows, dim) {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t\n", s.Key, s.Events, formatHours(s.Hours))
+		events += s.Events
+		hours += s.Hours
+	}
+	fmt.Fprintf(tw, "total\t%d\t%s\t\n", events, formatHours(hours))
+	return tw.Flush()
+}
+
+// Write stats.csv into the public folder and stats.html into each language folder.
+func renderStats(data scheduleData, locs []locale) error {
+	rows := buildStats(data)
+
+	f, err := os.Create(filepath.Join(publicDir, "stats.csv"))
+	if err != nil {
+		return err
+	}
+	if err := writeStatsCSV(f, rows); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	for _, l := range locs {
+		if err := renderStatsPage(l, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Data of stats.html (pages/stats.html).
+type statsPage struct {
+	pageData
+	Classes *statsTable // hours per class, nil without events
+	Details []classStats
+}
+
+// The charts and tables of one class.
+type classStats struct {
+	Class     string
+	Anchor    string
+	Weekly    *loadChart
+	Blocks    moduleBlockTable
+	Semesters statsTable
+	Types     statsTable
+}
+
+// Table of sums with one key column (the "stats-table" template).
+type statsTable struct {
+	locale
+	Head  string
+	Rows  []statsLine
+	Total *statsLine
+}
+
+type statsLine struct {
+	Key    string
+	Events int
+	Hours  string
+}
+
+// Module rows × block columns, hours per cell, with a total column.
+type moduleBlockTable struct {
+	Blocks []string
+	Rows   []moduleBlockRow
+	Totals []string // per block
+	Total  string
+}
+
+type moduleBlockRow struct {
+	Module string
+	Cells  []string // hours per block, "" for none
+	Total  string
+}
+
+func renderStatsPage(l locale, rows []statRow) error {
+	page := statsPage{pageData: l.newPage("stats.html", l.T("page.stats"), l.T("page.stats.sub"))}
+	if len(rows) > 0 {
+		t := newStatsTable(l, l.T("st.class"), sumStats(rows, func(r statRow) string { return r.Class }), true)
+		page.Classes = &t
+	}
+
+	byClass := map[string][]statRow{}
+	var classes []string
+	for _, r := range rows {
+		if _, ok := byClass[r.Class]; !ok {
+			classes = append(classes, r.Class)
+		}
+		byClass[r.Class] = append(byClass[r.Class], r)
+	}
+
+	for _, c := range classes {
+		cr := byClass[c]
+		page.Details = append(page.Details, classStats{
+			Class:     c,
+			Anchor:    sanitizeName(c),
+			Weekly:    weeklyLoadChart(sumStats(cr, func(r statRow) string { return r.Week })),
+			Blocks:    newModuleBlockTable(cr),
+			Semesters: newStatsTable(l, l.T("st.semester"), sumStats(cr, func(r statRow) string { return r.Semester }), false),
+			Types:     newStatsTable(l, l.T("st.type"), sumStats(cr, func(r statRow) string { return r.Type }), false),
+		})
+	}
+
+	return renderThemePage("stats.html", page)
+}
+
+func newStatsTable(l locale, head string, sums []statSum, total bool) statsTable {
+	t := statsTable{locale: l, Head: head}
+	var events int
+	var hours float64
+	for _, s := range sums {
+		t.Rows = append(t.Rows, statsLine{s.Key, s.Events, formatHours(s.Hours)})
+		events += s.Events
+		hours += s.Hours
+	}
+	if total {
+		t.Total = &statsLine{Events: events, Hours: formatHours(hours)}
+	}
+	return t
+}
+
+func newModuleBlockTable(rows []statRow) moduleBlockTable {
+	blocks := sumStats(rows, func(r statRow) string { return r.Block })
+	modules := sumStats(rows, func(r statRow) string { return r.Module })
+
+	hours := map[[2]string]float64{}
+	for _, r := range rows {
+		hours[[2]string{r.Module, r.Block}] += r.Hours
+	}
+
+	var t moduleBlockTable
+	var total float64
+	for _, bl := range blocks {
+		t.Blocks = append(t.Blocks, bl.Key)
+		t.Totals = append(t.Totals, formatHours(bl.Hours))
+		total += bl.Hours
+	}
+	t.Total = formatHours(total)
+
+	for _, m := range modules {
+		row := moduleBlockRow{Module: m.Key, Total: formatHours(m.Hours)}
+		for _, bl := range blocks {
+			cell := ""
+			if h := hours[[2]string{m.Key, bl.Key}]; h > 0 {
+				cell = formatHours(h)
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// Bar chart of hours per calendar week, drawn as inline SVG by the template.
+type loadChart struct {
+	Width, Height int
+	AxisY         int
+	Max           string
+	Bars          []loadBar
+	Labels        []chartLabel
+}
+
+type loadBar struct {
+	X, Y, W, H int
+	Title      string
+}
+
+type chartLabel struct {
+	X, Y int
+	Text string
+}
+
+// Weekly load chart. Weeks without events between the first and the last
+// week are drawn as gaps, so practice phases between blocks stay visible.
+func weeklyLoadChart(weeks []statSum) *loadChart {
+	if len(weeks) == 0 {
+		return nil
+	}
+
+	hours := map[string]float64{}
+	maxH := 0.0
+	for _, w := range weeks {
+		hours[w.Key] = w.Hours
+		if w.Hours > maxH {
+			maxH = w.Hours
+		}
+	}
+
+	first, err1 := time.Parse("2006-01-02", weeks[0].Key)
+	last, err2 := time.Parse("2006-01-02", weeks[len(weeks)-1].Key)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+
+	const (
+		barW   = 14
+		gap    = 3
+		chartH = 120
+		top    = 16
+		bottom = 18
+	)
+	n := int(last.Sub(first).Hours()/(24*7)) + 1
+	c := &loadChart{
+		Width:  n*(barW+gap) + gap,
+		Height: top + chartH + bottom,
+		AxisY:  top + chartH,
+		Max:    formatHours(maxH),
+	}
+
+	for i := 0; i < n; i++ {
+		monday := first.AddDate(0, 0, 7*i)
+		h := hours[monday.Format("2006-01-02")]
+		x := gap + i*(barW+gap)
+
+		if h > 0 {
+			bh := int(h / maxH * chartH)
+			if bh < 1 {
+				bh = 1
+			}
+			c.Bars = append(c.Bars, loadBar{x, top + chartH - bh, barW, bh, monday.Format(dateFormat) + ": " + formatHours(h) + " h"})
+		}
+		if i%4 == 0 {
+			c.Labels = append(c.Labels, chartLabel{x, c.Height - 4, monday.Format("02.01.")})
+		}
+	}
+	return c
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSemesterOf(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"08.12.2025", "WiSe 2025/26"},
+		{"05.01.2026", "WiSe 2025/26"},
+		{"31.03.2026", "WiSe 2025/26"},
+		{"01.04.2026", "SoSe 2026"},
+		{"30.09.2026", "SoSe 2026"},
+		{"01.10.2099", "WiSe 2099/00"},
+	}
+
+	for _, tt := range tests {
+		d, err := time.Parse(dateFormat, tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := semesterOf(d); got != tt.want {
+			t.Errorf("semesterOf(%s) = %q, want %q", tt.date, got, tt.want)
+		}
+	}
+}
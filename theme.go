@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Site pages are rendered from html/template files. The default theme in
+// themes/default is embedded into the binary; ASW_THEME_DIR points to a
+// folder with the same layout whose files take precedence:
+//
+//	layout.html          page frame (defines "layout")
+//	partials/*.html      header, nav, search, footer, infobox, calendar rows,
+//	                     handout cards
+//	pages/<name>.html    page content (defines "content"); pages/redirect.html
+//	                     replaces the whole "layout" for the language redirects
+//	css/site.css         inlined into every page
+//	js/*.js              site.js and search.js on every page, others per page
+//
+// A theme only needs the files it changes.
+
+//go:embed themes/default
+var embeddedThemes embed.FS
+
+var themeDir = getenv("ASW_THEME_DIR", "")
+
+// Data every page passes to the layout. The embedded locale provides the
+// translation methods ({{.T "key"}}) to all templates.
+type pageData struct {
+	locale
+	File     string // page file name in the language folder, e.g. "stats.html"
+	Title    string
+	Subtitle string
+	Nav      []navLink
+	Search   bool     // show the search box
+	Scripts  []string // extra js/ files loaded after site.js
+
+	DataAsOf     string // time of this run, for the header
+	Generated    string // the same as RFC 3339, for the stale check in site.js
+	StaleAfterMs int64
+}
+
+func (p pageData) base() pageData { return p }
+
+// Page in the language of l with the navigation of the secondary pages.
+func (l locale) newPage(file, title, subtitle string) pageData {
+	p := pageData{locale: l, File: file, Title: title, Subtitle: subtitle, Nav: pageNav(l), Search: true}
+	if !siteRun.generated.IsZero() {
+		p.DataAsOf = displayTime(siteRun.generated)
+		p.Generated = siteRun.generated.Format(time.RFC3339)
+		p.StaleAfterMs = siteRun.staleAfter.Milliseconds()
+	}
+	return p
+}
+
+type navLink struct {
+	Href      string
+	Label     string
+	Secondary bool
+}
+
+// Links shared by all navigation bars, after the page-specific ones.
+func siteNav(l locale, primary ...navLink) []navLink {
+	return append(primary,
+		navLink{"rooms.html", l.T("nav.rooms"), true},
+		navLink{"occupancy.html", l.T("nav.occupancy"), true},
+		navLink{"lecturers.html", l.T("nav.lecturers"), true},
+		navLink{"stats.html", l.T("nav.stats"), true},
+		navLink{"conflicts.html", l.T("nav.conflicts"), true},
+		navLink{sourcePage, l.T("nav.source"), true},
+	)
+}
+
+// Navigation of the secondary pages.
+func pageNav(l locale) []navLink {
+	return siteNav(l,
+		navLink{"index.html", l.T("nav.index"), false},
+		navLink{"all.html", l.T("nav.all"), true},
+	)
+}
+
+type theme struct {
+	files fs.FS
+	base  *template.Template // layout and partials
+
+	mu    sync.Mutex
+	pages map[string]*template.Template
+}
+
+// Theme files: ASW_THEME_DIR first, then the embedded default theme.
+type themeFS struct {
+	dir  string
+	base fs.FS
+}
+
+func (t themeFS) Open(name string) (fs.File, error) {
+	if t.dir != "" {
+		if f, err := os.DirFS(t.dir).Open(name); err == nil {
+			return f, nil
+		}
+	}
+	return t.base.Open(name)
+}
+
+var (
+	themeOnce   sync.Once
+	loadedTheme *theme
+	themeErr    error
+)
+
+// The theme selected by ASW_THEME_DIR, loaded on first use.
+func currentTheme() (*theme, error) {
+	themeOnce.Do(func() {
+		loadedTheme, themeErr = loadTheme(themeDir)
+		if themeErr != nil {
+			themeErr = fmt.Errorf("theme: %w", themeErr)
+		}
+	})
+	return loadedTheme, themeErr
+}
+
+func loadTheme(dir string) (*theme, error) {
+	def, err := fs.Sub(embeddedThemes, "themes/default")
+	if err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if st, err := os.Stat(dir); err != nil {
+			return nil, err
+		} else if !st.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", dir)
+		}
+	}
+
+	t := &theme{files: themeFS{dir: dir, base: def}, pages: map[string]*template.Template{}}
+
+	// Partials of the default theme plus any the custom theme adds.
+	partials, err := fs.Glob(def, "partials/*.html")
+	if err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		extra, _ := fs.Glob(os.DirFS(dir), "partials/*.html")
+		partials = append(partials, extra...)
+	}
+	sort.Strings(partials)
+
+	base := template.New("theme").Funcs(template.FuncMap{
+		"css": func(name string) (template.CSS, error) {
+			b, err := fs.ReadFile(t.files, path.Join("css", name))
+			return template.CSS(b), err
+		},
+		"js": func(name string) (template.JS, error) {
+			b, err := fs.ReadFile(t.files, path.Join("js", name))
+			return template.JS(b), err
+		},
+	})
+	seen := map[string]bool{}
+	for _, name := range append([]string{"layout.html"}, partials...) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if err := parseThemeFile(base, t.files, name); err != nil {
+			return nil, err
+		}
+	}
+	t.base = base
+
+	return t, nil
+}
+
+func parseThemeFile(tmpl *template.Template, files fs.FS, name string) error {
+	b, err := fs.ReadFile(files, name)
+	if err != nil {
+		return err
+	}
+	if _, err := tmpl.New(name).Parse(string(b)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Template for a page: pages/<file> if the theme has one, else pages/<fallback>.
+func (t *theme) page(file, fallback string) (*template.Template, error) {
+	name := path.Join("pages", file)
+	if _, err := fs.Stat(t.files, name); err != nil {
+		name = path.Join("pages", fallback)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p, ok := t.pages[name]; ok {
+		return p, nil
+	}
+	p, err := t.base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if err := parseThemeFile(p, t.files, name); err != nil {
+		return nil, err
+	}
+	t.pages[name] = p
+	return p, nil
+}
+
+// Render a page into its language folder. The page template is looked up by
+// the file name, e.g. pages/stats.html for stats.html.
+func renderThemePage(fallback string, data interface{ base() pageData }) error {
+	t, err := currentTheme()
+	if err != nil {
+		return err
+	}
+	pd := data.base()
+	p, err := t.page(pd.File, fallback)
+	if err != nil {
+		return err
+	}
+
+	if err := executePage(p, pd.path(pd.File), data); err != nil {
+		return fmt.Errorf("%s/%s: %w", pd.Lang, pd.File, err)
+	}
+	return nil
+}
+
+// Render a page outside the language folders, such as the language
+// redirects in the public root, from pages/<page> to path.
+func renderThemeFile(page, path string, data any) error {
+	t, err := currentTheme()
+	if err != nil {
+		return err
+	}
+	p, err := t.page(page, page)
+	if err != nil {
+		return err
+	}
+	if err := executePage(p, path, data); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func executePage(p *template.Template, path string, data any) error {
+	var buf bytes.Buffer
+	if err := p.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultThemePages(t *testing.T) {
+	th, err := loadTheme("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := locale{Lang: "de", Langs: []string{"de", "en"}}
+	base := l.newPage("index.html", "T", "S")
+	row := newCalendarRow(l, "DBWINFO-A04.ics", "DBWINFO-A04")
+	row.Modules = []calendarRow{newCalendarRow(l, "DBWINFO-A04/ibl-3.ics", "IBL III")}
+	stats := statsPage{pageData: base, Classes: &statsTable{locale: l, Head: "Klasse", Rows: []statsLine{{"DBWINFO-A04", 2, "3.00"}}}}
+	stats.Details = []classStats{{
+		Class:     "DBWINFO-A04",
+		Weekly:    &loadChart{Width: 20, Height: 154, Bars: []loadBar{{3, 16, 14, 120, "08.12.2025: 3.00 h"}}},
+		Blocks:    moduleBlockTable{Blocks: []string{"3. Block"}, Rows: []moduleBlockRow{{"IBL III", []string{"3.00"}, "3.00"}}, Totals: []string{"3.00"}, Total: "3.00"},
+		Semesters: statsTable{locale: l},
+		Types:     statsTable{locale: l},
+	}}
+	pages := map[string]any{
+		"calendars.html":   calendarsPage{pageData: base, Blocks: []blockView{{Name: "DBWINFO", Label: "DBWINFO", Count: 1, Subgroups: []subgroupView{{Class: "A04", Items: []calendarRow{row}}}}}},
+		"resources.html":   resourcesPage{pageData: base, Items: []calendarRow{row}},
+		"stats.html":       stats,
+		"conflicts.html":   conflictsPage{pageData: base, Sections: []conflictSection{{"Raum", []conflictItem{{"NK 1.12 · Di 09.12.2025", "a", "b"}}}}},
+		"occupancy.html":   occupancyPage{pageData: base, Weeks: []occupancyWeek{{Monday: "2025-12-08", Days: []string{"Mo 08.12."}, Rows: []occupancyRow{{"NK 2.05", [][]occupiedSlot{{{Start: "09:00", End: "10:30", Summary: "IBL III"}}}}}}}},
+		"timetable.html":   timetablePage{pageData: base, Class: "DBWINFO-A04", Hours: []ttHour{{0, "08:00"}}, Weeks: []ttWeek{{ID: "w2025-12-08", Next: "w2025-12-15", Columns: 5, Days: []ttDay{{Head: "Mo 08.12.", Events: []ttEvent{{Class: "tt-ev", Left: "0.00", Width: "100.00", Summary: "IBL III"}}}}}}},
+		"changes.html":     changesPage{pageData: base, KeepDays: 30, Groups: []changeGroup{{Detected: "09.12.2025 08:00", Changes: []changeItem{{Label: "Geändert", Summary: "IBL III", Diffs: []changedField{{"Raum", "NK: 2.05", "NK: 1.12"}}}}}}},
+		"handout.html":     handoutPage{pageData: base, Cards: []handoutCard{newHandoutCard(l, "DBWINFO-A04.ics", "DBWINFO-A04")}, Modules: []handoutCard{newHandoutCard(l, "DBWINFO-A04/ibl-3.ics", "IBL III")}},
+		"redirect.html":    redirectPage{File: "index.html", Default: "de", Langs: []string{"de", "en"}},
+		"preview.html":     base,
+		"help-google.html": base,
+	}
+	files, _ := fs.Glob(th.files, "pages/*.html")
+	if len(files) != len(pages) {
+		t.Errorf("default theme has %d pages, test covers %d", len(files), len(pages))
+	}
+	for name, data := range pages {
+		tmpl, err := th.page(name, name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := tmpl.ExecuteTemplate(&bytes.Buffer{}, "layout", data); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestThemeOverride(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"css/site.css":         "body{color:red}",
+		"partials/footer.html": `{{define "footer"}}<footer>Custom {{.Title}}</footer>{{end}}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	th, err := loadTheme(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := th.page("stats.html", "stats.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	l := locale{Lang: "en", Langs: []string{"de", "en"}}
+	data := statsPage{pageData: l.newPage("stats.html", "<Stats>", "")}
+	if err := tmpl.ExecuteTemplate(&buf, "layout", data); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"<style>body{color:red}</style>", "<footer>Custom &lt;Stats&gt;</footer>", "No events were parsed.", "function setReminders", "href='../de/stats.html'"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output", want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Free-room finder, reads rooms.json.
+let occupancy = null;
+async function loadOccupancy(){
+  if(occupancy) return occupancy;
+  const res = await fetch(siteRoot + 'rooms.json');
+  occupancy = await res.json();
+  return occupancy;
+}
+async function findFreeRooms(){
+  const date = document.getElementById('freeDate').value;
+  const time = document.getElementById('freeTime').value;
+  const list = document.getElementById('freeRooms');
+  list.innerHTML = '';
+  if(!date || !time) return;
+  const data = await loadOccupancy();
+  const free = [];
+  for(const room of data.rooms){
+    const day = room.days.find(d => d.date === date);
+    const slots = day ? day.slots : [];
+    const busy = slots.some(s => s.start <= time && time < s.end);
+    if(busy) continue;
+    const next = slots.filter(s => s.start > time).map(s => s.start).sort()[0];
+    free.push({label: room.label, until: next});
+  }
+  if(free.length === 0){
+    const li = document.createElement('li');
+    const row = document.createElement('div');
+    row.className = 'row';
+    const note = document.createElement('div');
+    note.className = 'small';
+    note.textContent = msg('noFreeRoom');
+    row.appendChild(note);
+    li.appendChild(row);
+    list.appendChild(li);
+    return;
+  }
+  for(const f of free){
+    const li = document.createElement('li');
+    const row = document.createElement('div');
+    row.className = 'row';
+    const name = document.createElement('div');
+    name.className = 'file';
+    name.textContent = f.label;
+    const until = document.createElement('div');
+    until.className = 'small';
+    until.textContent = f.until ? msg('freeUntil', f.until) : msg('freeRest');
+    row.appendChild(name);
+    row.appendChild(until);
+    li.appendChild(row);
+    list.appendChild(li);
+  }
+}
+document.addEventListener('DOMContentLoaded', () => {
+  const d = document.getElementById('freeDate');
+  if(d && !d.value){ d.value = new Date().toISOString().slice(0, 10); }
+});
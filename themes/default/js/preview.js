@@ -0,0 +1,178 @@
+// Calendar preview (preview.html?cal=<id>): month, week and agenda views of
+// one calendar, rendered from the JSON export (ics_files/<id>.json).
+const pv = {cal: null, events: [], byDay: {}, view: 'week', cursor: null};
+const DAY = 86400000;
+
+function el(tag, cls, text){
+  const e = document.createElement(tag);
+  if(cls) e.className = cls;
+  if(text !== undefined) e.textContent = text;
+  return e;
+}
+// Dates are handled as 'YYYY-MM-DD' strings in the calendar's timezone;
+// event times already carry the local offset, so no timezone math is needed.
+function toDate(key){ const [y, m, d] = key.split('-').map(Number); return new Date(Date.UTC(y, m - 1, d)); }
+function toKey(date){ return date.toISOString().slice(0, 10); }
+function addDays(key, n){ return toKey(new Date(toDate(key).getTime() + n * DAY)); }
+function mondayOf(key){ const d = toDate(key); return addDays(key, -((d.getUTCDay() + 6) % 7)); }
+function isoWeek(key){
+  const d = toDate(key);
+  const thursday = new Date(d.getTime() + (3 - (d.getUTCDay() + 6) % 7) * DAY);
+  const jan1 = new Date(Date.UTC(thursday.getUTCFullYear(), 0, 1));
+  return 1 + Math.floor((thursday - jan1) / DAY / 7);
+}
+function fmtDate(key){ const [y, m, d] = key.split('-'); return d + '.' + m + '.' + y; }
+const weekdays = msg('weekdays').split(',');
+function weekday(key){ return weekdays[(toDate(key).getUTCDay() + 6) % 7]; }
+function today(){
+  const d = new Date();
+  return d.getFullYear() + '-' + String(d.getMonth() + 1).padStart(2, '0') + '-' + String(d.getDate()).padStart(2, '0');
+}
+
+function eventNode(e, long){
+  const n = el('div', 'pv-ev' + (e.status === 'CANCELLED' ? ' cancelled' : '') + (e.status === 'TENTATIVE' ? ' tentative' : '') + (e.exam ? ' exam' : ''));
+  n.appendChild(el('span', 'pv-time', e.start.slice(11, 16) + (long ? '–' + e.end.slice(11, 16) : '')));
+  n.appendChild(el('span', 'pv-sum', ' ' + e.summary));
+  if(long){
+    const meta = [e.location, e.instructor, e.statusNote].filter(Boolean).join(' · ');
+    if(meta) n.appendChild(el('div', 'small', meta));
+  }
+  n.title = e.start.slice(11, 16) + '–' + e.end.slice(11, 16) + ' ' + e.summary + (e.location ? '\n' + e.location : '') + (e.description ? '\n\n' + e.description : '');
+  return n;
+}
+function studyWeek(from, to){
+  for(let k = from; k <= to; k = addDays(k, 1)){
+    for(const e of pv.byDay[k] || []){ if(e.week) return e.week.number; }
+  }
+  return 0;
+}
+function weekLabel(monday){
+  const sw = studyWeek(monday, addDays(monday, 6));
+  return msg('isoWeek', isoWeek(monday)) + (sw ? ' · ' + msg('studyWeek', sw) : '');
+}
+
+function renderMonth(body){
+  const first = pv.cursor.slice(0, 8) + '01';
+  const month = pv.cursor.slice(0, 7);
+  document.getElementById('pvRange').textContent = fmtDate(first).slice(3);
+  const grid = el('div', 'pv-month');
+  grid.appendChild(el('div', 'pv-head', msg('weekCol')));
+  for(const w of weekdays) grid.appendChild(el('div', 'pv-head', w));
+  for(let monday = mondayOf(first); monday.slice(0, 7) <= month; monday = addDays(monday, 7)){
+    grid.appendChild(el('div', 'pv-kw', String(isoWeek(monday))));
+    for(let i = 0; i < 7; i++){
+      const key = addDays(monday, i);
+      const cell = el('div', 'pv-cell' + (key.slice(0, 7) !== month ? ' other' : '') + (key === today() ? ' today' : ''));
+      cell.appendChild(el('div', 'pv-day', key.slice(8)));
+      for(const e of pv.byDay[key] || []) cell.appendChild(eventNode(e, false));
+      grid.appendChild(cell);
+    }
+  }
+  body.appendChild(grid);
+}
+function renderWeek(body){
+  const monday = mondayOf(pv.cursor);
+  document.getElementById('pvRange').textContent = fmtDate(monday) + ' – ' + fmtDate(addDays(monday, 6)) + ' · ' + weekLabel(monday);
+  const grid = el('div', 'pv-week');
+  for(let i = 0; i < 7; i++){
+    const key = addDays(monday, i);
+    const col = el('div', 'pv-col' + (key === today() ? ' today' : ''));
+    col.appendChild(el('div', 'pv-head', weekday(key) + ' ' + fmtDate(key).slice(0, 6)));
+    for(const e of pv.byDay[key] || []) col.appendChild(eventNode(e, true));
+    grid.appendChild(col);
+  }
+  body.appendChild(grid);
+}
+function renderAgenda(body){
+  const from = mondayOf(pv.cursor);
+  const to = addDays(from, 28);
+  document.getElementById('pvRange').textContent = fmtDate(from) + ' – ' + fmtDate(addDays(to, -1));
+  const list = el('div', 'pv-agenda');
+  let lastWeek = '';
+  for(let key = from; key < to; key = addDays(key, 1)){
+    const evs = pv.byDay[key];
+    if(!evs) continue;
+    const monday = mondayOf(key);
+    if(monday !== lastWeek){ list.appendChild(el('div', 'subhead', weekLabel(monday))); lastWeek = monday; }
+    const day = el('div', 'pv-aday');
+    day.appendChild(el('div', 'pv-head', weekday(key) + ' ' + fmtDate(key)));
+    for(const e of evs) day.appendChild(eventNode(e, true));
+    list.appendChild(day);
+  }
+  if(!list.children.length) list.appendChild(el('p', 'small', msg('noEvents')));
+  body.appendChild(list);
+}
+function render(){
+  const body = document.getElementById('pvBody');
+  body.innerHTML = '';
+  document.querySelectorAll('[data-view]').forEach(b => b.classList.toggle('btn-primary', b.dataset.view === pv.view));
+  ({month: renderMonth, week: renderWeek, agenda: renderAgenda})[pv.view](body);
+  const q = new URLSearchParams(location.search);
+  q.set('view', pv.view);
+  q.set('date', pv.cursor);
+  history.replaceState(null, '', '?' + q.toString());
+}
+function setView(v){ pv.view = v; render(); }
+function step(d){
+  if(pv.view === 'month'){
+    const [y, m] = pv.cursor.split('-').map(Number);
+    pv.cursor = toKey(new Date(Date.UTC(y, m - 1 + d, 1)));
+  }else{
+    pv.cursor = addDays(pv.cursor, d * (pv.view === 'week' ? 7 : 28));
+  }
+  render();
+}
+function goToday(){ pv.cursor = today(); render(); }
+
+function renderActions(id, reminders){
+  const name = id + '.ics';
+  const li = el('li');
+  if(reminders) li.dataset.reminders = '1';
+  const row = el('div', 'row');
+  const left = el('div', 'row-left');
+  left.appendChild(el('div', 'file', pv.cal.name + ' (' + msg('eventCount', pv.events.length) + ')'));
+  left.appendChild(el('div', 'small', name));
+  const actions = el('div', 'actions');
+  const sub = el('button', 'btn btn-primary', msg('subscribe'));
+  sub.onclick = () => subscribe(name);
+  const copy = el('button', 'btn', msg('copy'));
+  copy.onclick = () => copyUrl(name, copy);
+  const dl = el('a', 'btn', msg('download'));
+  dl.dataset.file = name;
+  dl.href = siteRoot + 'ics_files/' + name;
+  actions.append(sub, copy, dl);
+  row.append(left, actions);
+  li.appendChild(row);
+  document.getElementById('pvActions').appendChild(li);
+  setReminders(remindersOn());
+}
+
+async function loadPreview(){
+  const q = new URLSearchParams(location.search);
+  const id = q.get('cal');
+  const title = document.getElementById('pvTitle');
+  if(!id || id.includes('..')){ title.textContent = msg('noCalendar'); return; }
+  try{
+    const res = await fetch(siteRoot + 'ics_files/' + id.split('/').map(encodeURIComponent).join('/') + '.json');
+    if(!res.ok) throw new Error(res.status);
+    pv.cal = await res.json();
+  }catch(e){
+    title.textContent = msg('notFound', id);
+    return;
+  }
+  pv.events = pv.cal.events;
+  for(const e of pv.events){ (pv.byDay[e.start.slice(0, 10)] ||= []).push(e); }
+  title.textContent = pv.cal.name;
+  document.title = msg('previewTitle', pv.cal.name);
+  renderActions(id, q.get('r') === '1');
+
+  pv.view = ['month', 'week', 'agenda'].includes(q.get('view')) ? q.get('view') : 'week';
+  // Start at the given date, today, or the next/last week with events.
+  const t = today();
+  const days = Object.keys(pv.byDay).sort();
+  pv.cursor = /^\d{4}-\d{2}-\d{2}$/.test(q.get('date') || '') ? q.get('date')
+    : days.length === 0 || (days[0] <= t && t <= days[days.length - 1]) ? t
+    : days.find(d => d >= t) || days[days.length - 1];
+  render();
+}
+document.addEventListener('DOMContentLoaded', loadPreview);
@@ -0,0 +1,91 @@
+// Search box: loads search.json on first use and filters in the browser.
+let searchIndex = null;
+function fold(s){
+  return s.toLowerCase().replace(/ä/g, 'ae').replace(/ö/g, 'oe').replace(/ü/g, 'ue').replace(/ß/g, 'ss');
+}
+async function loadSearchIndex(){
+  if(searchIndex) return searchIndex;
+  const res = await fetch(siteRoot + 'search.json');
+  const entries = await res.json();
+  searchIndex = entries.map(e => ({e, name: fold(e.name + ' ' + e.id), terms: [
+    ...(e.modules || []).map(t => ['module', t]),
+    ...(e.instructors || []).map(t => ['lecturer', t]),
+    ...(e.rooms || []).map(t => ['room', t]),
+    ...(e.courses || []).map(t => ['block', t]),
+  ].map(([k, t]) => [k, t, fold(t)])}));
+  return searchIndex;
+}
+// Every word must match the name or one of the terms. Name matches rank first,
+// then broader calendars (class before module before block).
+let searchSeq = 0;
+async function runSearch(q){
+  const seq = ++searchSeq;
+  const list = document.getElementById('searchResults');
+  const words = fold(q).split(/\s+/).filter(Boolean);
+  list.innerHTML = '';
+  if(words.length === 0) return;
+  const index = await loadSearchIndex();
+  if(seq !== searchSeq) return; // a newer query is running
+  const order = ['class', 'exams', 'module', 'course', 'lecturer', 'room'];
+  const hits = [];
+  for(const item of index){
+    let score = 0;
+    const matched = new Set();
+    const ok = words.every(w => {
+      if(item.name.includes(w)){ score += 2; return true; }
+      const t = item.terms.filter(t => t[2].includes(w));
+      t.forEach(x => matched.add(x[1]));
+      if(t.length){ score += 1; return true; }
+      return false;
+    });
+    if(ok) hits.push({item, score, matched: [...matched]});
+  }
+  hits.sort((a, b) => b.score - a.score || order.indexOf(a.item.e.kind) - order.indexOf(b.item.e.kind) || a.item.e.name.localeCompare(b.item.e.name));
+  for(const h of hits.slice(0, 20)){
+    const li = document.createElement('li');
+    const row = document.createElement('div');
+    row.className = 'row';
+    const left = document.createElement('div');
+    left.className = 'row-left';
+    const name = document.createElement('a');
+    name.className = 'file';
+    name.href = h.item.e.page;
+    name.textContent = h.item.e.name;
+    const meta = document.createElement('div');
+    meta.className = 'small';
+    meta.textContent = msg('kind.' + h.item.e.kind) + (h.matched.length ? ' · ' + h.matched.slice(0, 3).join(', ') : '');
+    left.append(name, meta);
+    const actions = document.createElement('div');
+    actions.className = 'actions';
+    const show = document.createElement('a');
+    show.className = 'btn';
+    show.href = h.item.e.page;
+    show.textContent = msg('show');
+    const prev = document.createElement('a');
+    prev.className = 'btn';
+    prev.href = h.item.e.preview;
+    prev.textContent = msg('preview');
+    actions.append(show, prev);
+    row.append(left, actions);
+    li.appendChild(row);
+    list.appendChild(li);
+  }
+  if(hits.length === 0){
+    const li = document.createElement('li');
+    li.className = 'small';
+    li.textContent = msg('noMatch');
+    list.appendChild(li);
+  }
+}
+// Open collapsed module lists when a search result links into them.
+function revealHash(){
+  const id = location.hash.slice(1);
+  const target = id.startsWith('cal-') ? document.getElementById(id) : null;
+  if(!target) return;
+  const details = target.closest('details');
+  if(details) details.open = true;
+  target.classList.add('hit');
+  target.scrollIntoView({block: 'center'});
+}
+window.addEventListener('hashchange', revealHash);
+document.addEventListener('DOMContentLoaded', revealHash);
@@ -0,0 +1,82 @@
+// Shared by all pages: subscribe, copy and download actions, the
+// reminders toggle and the language switcher. siteRoot and messages are set
+// by the layout.
+
+// Text of the page language with %s/%d replaced by args in order.
+function msg(key, ...args){
+  let i = 0;
+  return (messages[key] || key).replace(/%[sd]/g, () => String(args[i++]));
+}
+function remindersOn(){
+  try{ return localStorage.getItem('aswReminders') === '1'; }catch(e){ return false; }
+}
+function filePath(name){
+  const row = document.querySelector('[data-file="' + name + '"]');
+  const li = row ? row.closest('li') : null;
+  if(remindersOn() && li && li.dataset.reminders){
+    return siteRoot + 'ics_files/reminders/' + name;
+  }
+  return siteRoot + 'ics_files/' + name;
+}
+function fileUrl(name){
+  return new URL(filePath(name), window.location.href).href;
+}
+function setReminders(on){
+  try{ localStorage.setItem('aswReminders', on ? '1' : '0'); }catch(e){}
+  document.querySelectorAll('a[data-file]').forEach(a => {
+    a.setAttribute('href', filePath(a.dataset.file));
+  });
+  // QR codes follow the feed variant of their row
+  document.querySelectorAll('img[data-qr]').forEach(img => {
+    const li = img.closest('li');
+    const dir = on && li && li.dataset.reminders ? 'reminders/' : '';
+    img.setAttribute('src', siteRoot + 'qr/' + dir + img.dataset.qr + '.' + img.dataset.kind + '.svg');
+  });
+}
+document.addEventListener('DOMContentLoaded', () => {
+  const box = document.getElementById('withReminders');
+  if(box){ box.checked = remindersOn(); }
+  setReminders(remindersOn());
+  // The pages are static: warn when the last run is too long ago.
+  const asof = document.querySelector('.asof[data-generated]');
+  if(asof && Date.now() - Date.parse(asof.dataset.generated) > Number(asof.dataset.staleAfter)){
+    const note = document.createElement('p');
+    note.className = 'stale';
+    note.textContent = msg('stale', asof.dataset.asOf);
+    asof.after(note);
+  }
+  // Keep the query and anchor when switching languages, and remember the choice.
+  document.querySelectorAll('a[data-lang]').forEach(a => {
+    a.href += location.search + location.hash;
+    a.addEventListener('click', () => {
+      try{ localStorage.setItem('aswLang', a.dataset.lang); }catch(e){}
+    });
+  });
+});
+function webcalUrl(httpsUrl){
+  return httpsUrl.replace(/^https?:\/\//i, 'webcal://');
+}
+async function copyUrl(name, btn){
+  const url = fileUrl(name);
+  try{
+    await navigator.clipboard.writeText(url);
+    flash(btn, msg('copied'), true);
+  }catch(e){
+    window.prompt(msg('copyPrompt'), url);
+  }
+}
+function subscribe(name){
+  const url = fileUrl(name);
+  const w = webcalUrl(url);
+  window.location.href = w;
+}
+function flash(btn, text, ok){
+  if(!btn) return;
+  const old = btn.textContent;
+  btn.textContent = text;
+  if(ok){ btn.classList.add('btn-success'); }
+  setTimeout(() => {
+    btn.textContent = old;
+    btn.classList.remove('btn-success');
+  }, 900);
+}
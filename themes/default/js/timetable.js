@@ -0,0 +1,34 @@
+// Week switching: shows the week of today (or the next week with events),
+// keeps the choice in the URL hash.
+const weeks = [...document.querySelectorAll('.ttweek')].map(s => s.id);
+function showWeek(id){
+  if(!weeks.includes(id)) return;
+  document.body.classList.remove('print-all');
+  for(const w of weeks){ document.getElementById(w).hidden = (w !== id); }
+  const pick = document.getElementById('weekPick');
+  if(pick) pick.value = id;
+  history.replaceState(null, '', '#' + id);
+}
+function currentWeek(){
+  const pick = document.getElementById('weekPick');
+  return pick ? pick.value : weeks[0];
+}
+function stepWeek(d){
+  const i = weeks.indexOf(currentWeek()) + d;
+  if(i >= 0 && i < weeks.length) showWeek(weeks[i]);
+}
+function printAll(){
+  for(const w of weeks){ document.getElementById(w).hidden = false; }
+  document.body.classList.add('print-all');
+  window.print();
+  showWeek(currentWeek());
+}
+document.addEventListener('DOMContentLoaded', () => {
+  if(weeks.length === 0) return;
+  const hash = location.hash.slice(1);
+  if(weeks.includes(hash)){ showWeek(hash); return; }
+  const d = new Date();
+  d.setDate(d.getDate() - ((d.getDay() + 6) % 7));
+  const monday = 'w' + d.getFullYear() + '-' + String(d.getMonth() + 1).padStart(2, '0') + '-' + String(d.getDate()).padStart(2, '0');
+  showWeek(weeks.find(w => w >= monday) || weeks[weeks.length - 1]);
+});
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Printable weekly timetables per class (timetable-<class>.html): days as
+// columns, a time axis, events as positioned blocks. Built from the same
+// aggregated events as the class .ics. On screen one week is shown at a
+// time; printing puts each week on its own landscape page.
+
+const (
+	ttPxPerHour = 48
+	ttMinHour   = 8  // axis never starts later than this
+	ttMaxHour   = 18 // ... or ends earlier than this
+)
+
+// File name of a class timetable page, relative to the language folder.
+func timetableFile(classKey string) string {
+	return "timetable-" + sanitizeName(classKey) + ".html"
+}
+
+// One positioned event block in a day column.
+type ttBlock struct {
+	Event ScheduleEvent
+	Lane  int
+	Lanes int
+}
+
+// Assign overlapping events of one day to side-by-side lanes. Events in
+// the same cluster of overlaps share the lane count, so widths line up.
+func layoutDay(events []ScheduleEvent) []ttBlock {
+	sorted := append([]ScheduleEvent(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Start.Equal(sorted[j].Start) {
+			return sorted[i].Start.Before(sorted[j].Start)
+		}
+		return sorted[i].End.After(sorted[j].End)
+	})
+
+	var out []ttBlock
+	var cluster []int        // indices into out
+	var laneEnds []time.Time // end of the last event per lane in the cluster
+	var clusterEnd time.Time
+
+	closeCluster := func() {
+		for _, i := range cluster {
+			out[i].Lanes = len(laneEnds)
+		}
+		cluster, laneEnds = nil, nil
+	}
+
+	for _, e := range sorted {
+		if len(cluster) > 0 && !e.Start.Before(clusterEnd) {
+			closeCluster()
+		}
+
+		lane := -1
+		for l, end := range laneEnds {
+			if !e.Start.Before(end) {
+				lane = l
+				break
+			}
+		}
+		if lane < 0 {
+			lane = len(laneEnds)
+			laneEnds = append(laneEnds, e.End)
+		} else {
+			laneEnds[lane] = e.End
+		}
+
+		if len(cluster) == 0 || e.End.After(clusterEnd) {
+			clusterEnd = e.End
+		}
+		cluster = append(cluster, len(out))
+		out = append(out, ttBlock{Event: e, Lane: lane})
+	}
+	closeCluster()
+
+	return out
+}
+
+// Hour range of the time axis for a class, covering all its events.
+func timetableHours(events []ScheduleEvent) (int, int) {
+	from, to := ttMinHour, ttMaxHour
+	for _, e := range events {
+		if h := e.Start.Hour(); h < from {
+			from = h
+		}
+		h := e.End.Hour()
+		if e.End.Minute() > 0 {
+			h++
+		}
+		if e.End.Day() != e.Start.Day() {
+			h = 24
+		}
+		if h > to {
+			to = h
+		}
+	}
+	return from, to
+}
+
+// Write a timetable page for every class into the language folder.
+func renderTimetables(data scheduleData, l locale) error {
+	for _, classKey := range eventKeys(data.Classes) {
+		if err := renderTimetablePage(l, timetableFile(classKey), classKey, data.Classes[classKey]); err != nil {
+			return fmt.Errorf("timetable %s: %w", classKey, err)
+		}
+	}
+	return nil
+}
+
+// Data of a class timetable (pages/timetable.html). The time axis is the
+// same in every week.
+type timetablePage struct {
+	pageData
+	Class      string
+	Weeks      []ttWeek
+	Hours      []ttHour
+	HourHeight int
+	BodyHeight int
+}
+
+type ttWeek struct {
+	ID         string // section id, "w" + Monday
+	Title      string
+	Prev, Next string // IDs of the neighbouring weeks, "" at either end
+	Columns    int    // 5 to 7; weekend columns only when used
+	Days       []ttDay
+}
+
+type ttDay struct {
+	Head   string
+	Events []ttEvent
+}
+
+type ttHour struct {
+	Top   int
+	Label string
+}
+
+// One positioned event block, with its CSS classes and geometry.
+type ttEvent struct {
+	Class       string
+	Top, Height int
+	Left, Width string // percent of the day column
+	Title       string
+	Time        string
+	Summary     string
+	Room        string
+	Instructor  string
+}
+
+func renderTimetablePage(l locale, file, classKey string, events []ScheduleEvent) error {
+	page := timetablePage{pageData: l.newPage(file, l.T("page.timetable", classKey), l.T("page.timetable.sub")), Class: classKey}
+	page.Scripts = []string{"timetable.js"}
+
+	// Events per week (Monday) and day.
+	weeks := map[string]map[string][]ScheduleEvent{}
+	for _, e := range events {
+		monday := mondayOf(e.Start).Format("2006-01-02")
+		day := e.Start.Format("2006-01-02")
+		if weeks[monday] == nil {
+			weeks[monday] = map[string][]ScheduleEvent{}
+		}
+		weeks[monday][day] = append(weeks[monday][day], e)
+	}
+	order := make([]string, 0, len(weeks))
+	for w := range weeks {
+		order = append(order, w)
+	}
+	sort.Strings(order)
+
+	fromHour, toHour := timetableHours(events)
+	page.HourHeight = ttPxPerHour
+	page.BodyHeight = (toHour - fromHour) * ttPxPerHour
+	for h := fromHour; h < toHour; h++ {
+		page.Hours = append(page.Hours, ttHour{(h - fromHour) * ttPxPerHour, fmt.Sprintf("%02d:00", h)})
+	}
+
+	for i, w := range order {
+		start, _ := time.Parse("2006-01-02", w)
+		days := weeks[w]
+
+		// Saturday and Sunday columns only in weeks that have events on them.
+		n := 5
+		for d := range days {
+			t, _ := time.Parse("2006-01-02", d)
+			if t.Weekday() == time.Saturday && n < 6 {
+				n = 6
+			}
+			if t.Weekday() == time.Sunday {
+				n = 7
+			}
+		}
+
+		week := ttWeek{ID: "w" + w, Title: weekTitle(l, start, days), Columns: n}
+		if i > 0 {
+			week.Prev = "w" + order[i-1]
+		}
+		if i < len(order)-1 {
+			week.Next = "w" + order[i+1]
+		}
+		for d := 0; d < n; d++ {
+			day := start.AddDate(0, 0, d)
+			col := ttDay{Head: l.weekday(day.Weekday()) + " " + day.Format("02.01.")}
+			for _, blk := range layoutDay(days[day.Format("2006-01-02")]) {
+				col.Events = append(col.Events, newTTEvent(blk, fromHour))
+			}
+			week.Days = append(week.Days, col)
+		}
+		page.Weeks = append(page.Weeks, week)
+	}
+
+	return renderThemePage("timetable.html", page)
+}
+
+// "7. Studienwoche · 08.12.2025 – 14.12.2025", or just the dates when the
+// sked week header is unknown.
+func weekTitle(l locale, monday time.Time, days map[string][]ScheduleEvent) string {
+	dates := monday.Format(dateFormat) + " – " + monday.AddDate(0, 0, 6).Format(dateFormat)
+	week := 0
+	for _, evs := range days {
+		for _, e := range evs {
+			if e.Week > 0 && (week == 0 || e.Week < week) {
+				week = e.Week
+			}
+		}
+	}
+	if week == 0 {
+		return dates
+	}
+	return l.T("tt.studyWeek", week, dates)
+}
+
+func newTTEvent(blk ttBlock, fromHour int) ttEvent {
+	e := blk.Event
+	dayStart := time.Date(e.Start.Year(), e.Start.Month(), e.Start.Day(), fromHour, 0, 0, 0, e.Start.Location())
+
+	top := int(e.Start.Sub(dayStart).Minutes() * ttPxPerHour / 60)
+	height := int(e.End.Sub(e.Start).Minutes() * ttPxPerHour / 60)
+	if height < 14 {
+		height = 14
+	}
+	width := 100.0 / float64(blk.Lanes)
+	left := width * float64(blk.Lane)
+
+	classes := "tt-ev"
+	switch {
+	case e.Status == StatusCancelled:
+		classes += " cancelled"
+	case e.Status == StatusTentative:
+		classes += " tentative"
+	}
+	if isExamEvent(e) {
+		classes += " exam"
+	}
+
+	times := e.Start.Format("15:04") + "–" + e.End.Format("15:04")
+	title := times + " " + e.Summary
+	if e.StatusNote != "" {
+		title += " (" + e.StatusNote + ")"
+	}
+
+	return ttEvent{
+		Class:      classes,
+		Top:        top,
+		Height:     height,
+		Left:       fmt.Sprintf("%.2f", left),
+		Width:      fmt.Sprintf("%.2f", width),
+		Title:      title,
+		Time:       times,
+		Summary:    e.Summary,
+		Room:       csvRoom(e),
+		Instructor: e.Instructor,
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLayoutDay(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2025, 12, 8, h, m, 0, 0, time.UTC) }
+	ev := func(name string, from, to time.Time) ScheduleEvent {
+		return ScheduleEvent{Summary: name, Start: from, End: to}
+	}
+
+	blocks := layoutDay([]ScheduleEvent{
+		ev("C", at(10, 0), at(11, 30)),
+		ev("A", at(9, 0), at(10, 30)),
+		ev("B", at(9, 0), at(9, 45)),
+		ev("D", at(13, 0), at(14, 30)),
+	})
+
+	// A, B and C form one cluster of two lanes (C reuses B's lane); D is alone.
+	want := map[string][2]int{"A": {0, 2}, "B": {1, 2}, "C": {1, 2}, "D": {0, 1}}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d", len(blocks), len(want))
+	}
+	for _, b := range blocks {
+		if got := [2]int{b.Lane, b.Lanes}; got != want[b.Event.Summary] {
+			t.Errorf("%s: lane/lanes = %v, want %v", b.Event.Summary, got, want[b.Event.Summary])
+		}
+	}
+}